@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/zinrai/sevalet/internal/executor"
+)
+
+// childCmd is re-executed by the daemon to set up a command's process
+// before exec. It is not meant to be run by hand.
+var childCmd = &cobra.Command{
+	Use:                executor.ChildCommand,
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		executor.RunChild()
+	},
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zinrai/sevalet/internal/auth"
+)
+
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an API key",
+	Long: `Generate a random API key and print it with its hash. Give the key to the
+client and put the hash in the auth section of the API configuration.`,
+	Example: `  sevalet keygen`,
+	Args:    cobra.NoArgs,
+	RunE:    runKeygen,
+}
+
+func runKeygen(cmd *cobra.Command, args []string) error {
+	key := auth.GenerateKey()
+	fmt.Fprintf(cmd.OutOrStdout(), "key:  %s\nhash: %s\n", key, auth.HashKey(key))
+	return nil
+}
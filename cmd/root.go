@@ -34,4 +34,6 @@ func init() {
 	// Add subcommands
 	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(childCmd)
+	rootCmd.AddCommand(keygenCmd)
 }
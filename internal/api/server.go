@@ -1,32 +1,68 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/zinrai/sevalet/internal/approval"
+	"github.com/zinrai/sevalet/internal/auth"
+	"github.com/zinrai/sevalet/internal/certs"
 	"github.com/zinrai/sevalet/internal/config"
 	grpcclient "github.com/zinrai/sevalet/internal/grpc"
+	"github.com/zinrai/sevalet/internal/idempotency"
 	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/plan"
+	"github.com/zinrai/sevalet/internal/webhook"
+	"github.com/zinrai/sevalet/pb"
 )
 
+// HeaderIdentity names the identity a request is made on behalf of when
+// authentication isn't configured. It is taken as given, so it is only
+// written to the API's log and scopes idempotency keys; the daemon isn't
+// told about it.
+const HeaderIdentity = "X-Sevalet-Identity"
+
+// contextKey is the type of the request context keys of this package
+type contextKey int
+
+const authContextKey contextKey = iota
+
+// authResult is the outcome of authenticating a request
+type authResult struct {
+	principal *models.Principal
+	err       error
+}
+
 // Server represents the HTTP API server
 type Server struct {
 	config     *config.APIConfig
 	httpServer *http.Server
 	grpcClient *grpcclient.Client
+	webhooks   *webhook.Dispatcher
+	requests   *idempotency.Store
+	keys       *auth.Keyring
+
+	// Forwarding of schedule completions to the webhooks
+	stopCompletions context.CancelFunc
+	completionsDone chan struct{}
 }
 
 // New creates a new API server instance
 func New(config *config.APIConfig) *Server {
 	return &Server{
-		config: config,
+		config:   config,
+		requests: idempotency.New(time.Duration(config.IdempotencyTTL) * time.Second),
 	}
 }
 
@@ -51,14 +87,50 @@ func (s *Server) Start() error {
 		s.grpcClient = grpcClient
 	}
 
+	// Prepare completion webhooks
+	if s.config.Webhooks != nil {
+		webhooks, err := webhook.New(s.config.Webhooks, s.logDeliveryAttempt)
+		if err != nil {
+			return err
+		}
+		s.webhooks = webhooks
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopCompletions = cancel
+		s.completionsDone = make(chan struct{})
+		go s.forwardScheduleCompletions(ctx)
+	}
+
+	// Load API keys and JWT signing keys
+	if s.config.Auth != nil {
+		keys, err := auth.NewKeyring(s.config.Auth)
+		if err != nil {
+			return fmt.Errorf("failed to load authentication keys: %w", err)
+		}
+		s.keys = keys
+	} else {
+		log.Printf("WARNING: No authentication configured, requests are not authenticated")
+	}
+
 	// Setup HTTP routes
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", s.healthHandler)
 	mux.HandleFunc("/ready", s.readyHandler)
-	mux.HandleFunc("/execute", s.executeHandler)
+	mux.HandleFunc("/execute", s.withAuth(models.ActionExecute, s.withIdempotency(s.withCallback("execute.completed", s.executeHandler))))
+	mux.HandleFunc("/plan", s.withAuth(models.ActionPlan, s.planHandler))
+	mux.HandleFunc("/apply", s.withAuth(models.ActionPlan, s.withIdempotency(s.withCallback("apply.completed", s.applyHandler))))
+	mux.HandleFunc("/pipeline", s.withAuth(models.ActionPipeline, s.withIdempotency(s.withCallback("pipeline.completed", s.pipelineHandler))))
+	mux.HandleFunc("/runbooks/{name}", s.withAuth(models.ActionRunbook, s.withIdempotency(s.withCallback("runbook.completed", s.runbookHandler))))
+	mux.HandleFunc("/schedules", s.withAuth(models.ActionRead, s.schedulesHandler))
+	mux.HandleFunc("/deliveries", s.withAuth(models.ActionRead, s.deliveriesHandler))
+	mux.HandleFunc("/deliveries/{id}", s.withAuth(models.ActionRead, s.deliveryHandler))
+	mux.HandleFunc("/approvals", s.withAuth(models.ActionRead, s.approvalsHandler))
+	mux.HandleFunc("GET /approvals/{id}", s.withAuth(models.ActionRead, s.approvalHandler))
+	mux.HandleFunc("POST /approvals/{id}", s.withAuth(models.ActionApprove, s.approvalHandler))
 
-	// Wrap with logging middleware
-	handler := s.loggingMiddleware(mux)
+	// Wrap with authentication and logging middleware. Authentication
+	// comes first so the identity is logged.
+	handler := s.authMiddleware(s.loggingMiddleware(mux))
 
 	// Create HTTP server
 	s.httpServer = &http.Server{
@@ -68,6 +140,13 @@ func (s *Server) Start() error {
 		WriteTimeout:   time.Duration(s.config.RequestTimeout+10) * time.Second,
 		MaxHeaderBytes: 1 << 20, // 1MB
 	}
+	if s.config.TLS != nil {
+		reloader, err := certs.New(s.config.TLS, s.logCertificateReload)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = reloader.TLSConfig()
+	}
 
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
@@ -76,8 +155,15 @@ func (s *Server) Start() error {
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
-		log.Printf("API server listening on %s", s.config.ListenAddress)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if s.httpServer.TLSConfig != nil {
+			log.Printf("API server listening on %s (TLS)", s.config.ListenAddress)
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("API server listening on %s", s.config.ListenAddress)
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
@@ -104,6 +190,13 @@ func (s *Server) Shutdown() error {
 		log.Printf("Error shutting down HTTP server: %v", err)
 	}
 
+	// Wait for requests running in the background to report their results
+	if s.stopCompletions != nil {
+		s.stopCompletions()
+		<-s.completionsDone
+	}
+	s.webhooks.Close()
+
 	// Close gRPC client
 	if s.grpcClient != nil {
 		if err := s.grpcClient.Close(); err != nil {
@@ -182,6 +275,10 @@ func (s *Server) executeHandler(w http.ResponseWriter, r *http.Request) {
 		s.respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !s.allowsCommand(r, request.Command) {
+		s.respondWithError(w, http.StatusForbidden, errCommandScope)
+		return
+	}
 
 	// Ensure we have a gRPC connection
 	if s.grpcClient == nil {
@@ -197,34 +294,974 @@ func (s *Server) executeHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(request.Timeout)*time.Second)
 	defer cancel()
 
-	resp, err := s.grpcClient.Execute(ctx, request.Command, request.Args, request.Timeout)
+	resp, err := s.grpcClient.Execute(ctx, request, s.daemonIdentity(r))
 	if err != nil {
 		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to execute command")
 		return
 	}
 
-	// Build HTTP response
+	// The command waits for approval
+	if resp.Approval != nil {
+		s.respondWithJSON(w, http.StatusAccepted, models.ApprovalResponse{
+			Success:  true,
+			Approval: approvalFromMessage(resp.Approval),
+		})
+		return
+	}
+
+	// Send response
+	s.respondWithJSON(w, http.StatusOK, executeResponse(resp))
+}
+
+// executeResponse builds the HTTP response for a daemon's execute response
+func executeResponse(resp *pb.ExecuteResponse) models.HTTPResponse {
 	httpResp := models.HTTPResponse{
+		Success:        resp.Success,
+		Succeeded:      resp.Succeeded,
+		ExitCode:       int(resp.ExitCode),
+		ExecutionTime:  resp.ExecutionTime,
+		LimitExceeded:  resp.LimitExceeded,
+		PeakMemory:     resp.PeakMemoryBytes,
+		CPUTime:        resp.CpuTime,
+		Parsed:         resp.Parsed,
+		ParseError:     resp.ParseError,
+		Cached:         resp.Cached,
+		CacheAge:       resp.CacheAge,
+		TruncatedLines: int(resp.TruncatedLines),
+	}
+	var lines []models.OutputLine
+	for _, l := range resp.Output {
+		lines = append(lines, models.OutputLine{
+			Stream:   l.Stream,
+			OffsetNs: l.OffsetNs,
+			Line:     string(l.Data),
+		})
+	}
+	httpResp.SetOutput(resp.Stdout, resp.Stderr, lines)
+
+	if !resp.Success {
+		httpResp.Error = executeError(resp.ErrorMessage, resp.ErrorCode)
+		httpResp.ErrorCode = resp.ErrorCode
+		httpResp.NextAllowed = resp.NextAllowed
+	}
+
+	return httpResp
+}
+
+// executeError simplifies a daemon's error message for a command for
+// security, keeping the ones that tell the caller what to fix: those with
+// an error code and a few others
+func executeError(message, code string) string {
+	if code != "" || message == "command not allowed" || message == "argument not allowed" ||
+		message == approval.ErrNoIdentity.Error() || message == approval.ErrFull.Error() ||
+		strings.HasPrefix(message, models.ErrFilterNotAllowed.Error()) {
+		return message
+	}
+	return "Command execution failed"
+}
+
+// planHandler handles /plan endpoint
+func (s *Server) planHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check body size
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize))
+
+	// Parse request
+	request, err := models.NewExecuteRequestFromJSON(r.Body)
+	if err != nil {
+		s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Basic validation
+	if err := request.Validate(); err != nil {
+		s.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !s.allowsCommand(r, request.Command) {
+		s.respondWithError(w, http.StatusForbidden, errCommandScope)
+		return
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	// Forward to daemon
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.Plan(ctx, request, s.daemonIdentity(r))
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to plan command")
+		return
+	}
+
+	// Build HTTP response
+	httpResp := models.PlanResponse{
+		Success: resp.Success,
+		Token:   resp.Token,
+	}
+	if resp.Success {
+		var planned models.Plan
+		if err := json.Unmarshal(resp.Plan, &planned); err != nil {
+			s.respondWithError(w, http.StatusInternalServerError, "Failed to plan command")
+			return
+		}
+		httpResp.Plan = &planned
+	} else {
+		httpResp.Error = executeError(resp.ErrorMessage, resp.ErrorCode)
+		httpResp.ErrorCode = resp.ErrorCode
+		httpResp.NextAllowed = resp.NextAllowed
+	}
+
+	// Send response
+	s.respondWithJSON(w, http.StatusOK, httpResp)
+}
+
+// applyHandler handles /apply endpoint
+func (s *Server) applyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check body size
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize))
+
+	// Parse request
+	var request models.ApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Token == "" {
+		s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	// Forward to daemon. The plan's timeout is at most the daemon's
+	// max_execution_time, which the request timeout should cover.
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.Apply(ctx, request.Token, s.daemonIdentity(r))
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to apply plan")
+		return
+	}
+
+	switch resp.ErrorMessage {
+	case plan.ErrInvalid.Error():
+		s.respondWithError(w, http.StatusBadRequest, resp.ErrorMessage)
+		return
+	case plan.ErrIdentity.Error():
+		s.respondWithError(w, http.StatusForbidden, resp.ErrorMessage)
+		return
+	case plan.ErrExpired.Error(), plan.ErrApplied.Error():
+		s.respondWithError(w, http.StatusConflict, resp.ErrorMessage)
+		return
+	}
+
+	// The command waits for approval
+	if resp.Approval != nil {
+		s.respondWithJSON(w, http.StatusAccepted, models.ApprovalResponse{
+			Success:  true,
+			Approval: approvalFromMessage(resp.Approval),
+		})
+		return
+	}
+
+	// Send response
+	s.respondWithJSON(w, http.StatusOK, executeResponse(resp))
+}
+
+// pipelineHandler handles /pipeline endpoint
+func (s *Server) pipelineHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check body size
+	r.Body = http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize))
+
+	// Parse request
+	request, err := models.NewPipelineRequestFromJSON(r.Body)
+	if err != nil {
+		s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Basic validation
+	if err := request.Validate(); err != nil {
+		s.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, stage := range request.Stages {
+		if !s.allowsCommand(r, stage.Command) {
+			s.respondWithError(w, http.StatusForbidden, errCommandScope)
+			return
+		}
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	// Forward to daemon
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(request.Timeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.ExecutePipeline(ctx, request, s.daemonIdentity(r))
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to execute pipeline")
+		return
+	}
+
+	// Build HTTP response
+	httpResp := models.PipelineResponse{
 		Success:       resp.Success,
-		ExitCode:      int(resp.ExitCode),
-		Stdout:        resp.Stdout,
-		Stderr:        resp.Stderr,
+		Succeeded:     resp.Succeeded,
 		ExecutionTime: resp.ExecutionTime,
 	}
+	var stderr [][]byte
+	for _, stage := range resp.Stages {
+		httpResp.Stages = append(httpResp.Stages, models.StageResponse{
+			Command:       stage.Command,
+			ExitCode:      int(stage.ExitCode),
+			Signal:        stage.Signal,
+			Succeeded:     stage.Succeeded,
+			LimitExceeded: stage.LimitExceeded,
+			PeakMemory:    stage.PeakMemoryBytes,
+			CPUTime:       stage.CpuTime,
+			Error:         stage.ErrorMessage,
+		})
+		stderr = append(stderr, stage.Stderr)
+	}
+	httpResp.SetOutput(resp.Stdout, stderr)
 
 	if !resp.Success {
 		// Simplify error message for security
-		if resp.ErrorMessage == "command not allowed" || resp.ErrorMessage == "argument not allowed" {
+		if resp.ErrorCode != "" || resp.ErrorMessage == "command not allowed" || resp.ErrorMessage == "argument not allowed" ||
+			resp.ErrorMessage == models.ErrApprovalRequired.Error() || resp.ErrorMessage == "pipeline stage failed" {
 			httpResp.Error = resp.ErrorMessage
 		} else {
-			httpResp.Error = "Command execution failed"
+			httpResp.Error = "Pipeline execution failed"
+		}
+		httpResp.ErrorCode = resp.ErrorCode
+		httpResp.NextAllowed = resp.NextAllowed
+	}
+
+	// Send response
+	s.respondWithJSON(w, http.StatusOK, httpResp)
+}
+
+// runbookHandler handles /runbooks/{name} endpoint
+func (s *Server) runbookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if principal := s.principal(r); principal != nil && !principal.AllowsRunbook(r.PathValue("name")) {
+		s.respondWithError(w, http.StatusForbidden, "runbook not allowed for this identity")
+		return
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	// Forward to daemon. Runbooks take as long as their steps, so they
+	// are bound by the API's request timeout.
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.ExecuteRunbook(ctx, r.PathValue("name"), s.daemonIdentity(r))
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to execute runbook")
+		return
+	}
+
+	if resp.ErrorMessage == "runbook not found" {
+		s.respondWithError(w, http.StatusNotFound, resp.ErrorMessage)
+		return
+	}
+
+	// Build HTTP response
+	httpResp := models.RunbookResponse{
+		Success:       resp.Success,
+		Succeeded:     resp.Succeeded,
+		Runbook:       r.PathValue("name"),
+		ExecutionTime: resp.ExecutionTime,
+		Error:         resp.ErrorMessage,
+		ErrorCode:     resp.ErrorCode,
+		NextAllowed:   resp.NextAllowed,
+	}
+	for _, step := range resp.Steps {
+		stepResp := models.StepResponse{
+			Name:          step.Name,
+			Command:       step.Command,
+			Args:          step.Args,
+			Status:        step.Status,
+			Compensates:   step.Compensates,
+			ExitCode:      int(step.ExitCode),
+			ExecutionTime: step.ExecutionTime,
+			Error:         step.ErrorMessage,
+		}
+		stepResp.SetOutput(step.Stdout, step.Stderr)
+		httpResp.Steps = append(httpResp.Steps, stepResp)
+	}
+
+	// Send response
+	s.respondWithJSON(w, http.StatusOK, httpResp)
+}
+
+// schedulesHandler handles GET /schedules
+func (s *Server) schedulesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
 		}
+		s.grpcClient = grpcClient
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.ListSchedules(ctx)
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to list schedules")
+		return
+	}
+
+	// Build HTTP response
+	httpResp := models.SchedulesResponse{
+		Success:   true,
+		Schedules: []models.ScheduleStatus{},
+	}
+	for _, schedule := range resp.Schedules {
+		httpResp.Schedules = append(httpResp.Schedules, models.ScheduleStatus{
+			Schedule: models.Schedule{
+				Name:    schedule.Name,
+				Cron:    schedule.Cron,
+				Command: schedule.Command,
+				Args:    schedule.Args,
+				Runbook: schedule.Runbook,
+				Timeout: int(schedule.Timeout),
+				Jitter:  int(schedule.Jitter),
+				Overlap: schedule.Overlap,
+
+				CallbackURL: schedule.CallbackUrl,
+			},
+			Running:      schedule.Running,
+			Queued:       schedule.Queued,
+			LastRun:      schedule.LastRun,
+			LastStatus:   schedule.LastStatus,
+			LastDuration: schedule.LastDuration,
+			LastError:    schedule.LastError,
+			NextRun:      schedule.NextRun,
+			Runs:         schedule.Runs,
+			Skipped:      schedule.Skipped,
+		})
 	}
 
 	// Send response
 	s.respondWithJSON(w, http.StatusOK, httpResp)
 }
 
+// withIdempotency replays the stored response of an earlier request with the
+// same Idempotency-Key header instead of running it again. A retry while the
+// first request is still running waits for it. Server errors aren't stored,
+// as the request may not have run.
+func (s *Server) withIdempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > 255 {
+			s.respondWithError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		// Check body size
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize)))
+		if err != nil {
+			s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, body)
+
+		// Keys are scoped to the caller, so one can't replay another's
+		// response
+		if identity := s.identity(r); identity != "" {
+			key = identity + "\x00" + key
+		}
+
+		for {
+			entry, owner, err := s.requests.Begin(key, fingerprint)
+			switch {
+			case errors.Is(err, idempotency.ErrMismatch):
+				s.respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			case err != nil:
+				s.respondWithError(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+
+			if owner {
+				// A handler that panics must not leave the key in
+				// progress, which would block every retry
+				done := false
+				defer func() {
+					if !done {
+						s.requests.Abort(key)
+					}
+				}()
+
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				rec := newResponseRecorder()
+				next(rec, r)
+
+				response := &idempotency.Response{
+					StatusCode: rec.statusCode,
+					Header:     rec.header,
+					Body:       rec.body.Bytes(),
+				}
+				if rec.statusCode >= 500 {
+					s.requests.Abort(key)
+				} else {
+					s.requests.Finish(key, response)
+				}
+				done = true
+				writeResponse(w, response)
+				return
+			}
+
+			response, err := entry.Wait(r.Context())
+			if err != nil {
+				return
+			}
+			if response == nil {
+				// The first request failed, run this one instead
+				continue
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			writeResponse(w, response)
+			return
+		}
+	}
+}
+
+// writeResponse sends a stored response
+func writeResponse(w http.ResponseWriter, response *idempotency.Response) {
+	for k, v := range response.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(response.StatusCode)
+	w.Write(response.Body)
+}
+
+// withCallback runs a request in the background if its JSON body sets
+// callback_url, answering 202 Accepted with the delivery ID. The status and
+// body the handler responds with are sent to the callback URL.
+func (s *Server) withCallback(event string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+
+		// Check body size
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize)))
+		if err != nil {
+			s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var callback models.Callback
+		if json.Unmarshal(body, &callback) != nil || callback.URL == "" {
+			next(w, r)
+			return
+		}
+		if !s.webhooks.Allows(callback.URL) {
+			s.respondWithError(w, http.StatusBadRequest, "callback_url not allowed")
+			return
+		}
+
+		// The request outlives the connection
+		background := r.Clone(context.WithoutCancel(r.Context()))
+		background.Body = io.NopCloser(bytes.NewReader(body))
+
+		id := s.webhooks.Dispatch(event, callback.URL, func() any {
+			rec := newResponseRecorder()
+			next(rec, background)
+
+			response := rec.body.Bytes()
+			if !json.Valid(response) {
+				response, _ = json.Marshal(rec.body.String())
+			}
+			return models.CompletedRequest{
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				StatusCode: rec.statusCode,
+				Response:   response,
+			}
+		})
+
+		s.respondWithJSON(w, http.StatusAccepted, models.AcceptedResponse{
+			Success:  true,
+			Delivery: id,
+		})
+	}
+}
+
+// deliveriesHandler handles GET /deliveries
+func (s *Server) deliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.respondWithJSON(w, http.StatusOK, models.DeliveriesResponse{
+		Success:    true,
+		Deliveries: s.webhooks.Deliveries(),
+	})
+}
+
+// deliveryHandler handles GET /deliveries/{id}
+func (s *Server) deliveryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	delivery, ok := s.webhooks.Delivery(r.PathValue("id"))
+	if !ok {
+		s.respondWithError(w, http.StatusNotFound, "delivery not found")
+		return
+	}
+
+	s.respondWithJSON(w, http.StatusOK, models.DeliveryResponse{
+		Success:  true,
+		Delivery: &delivery,
+	})
+}
+
+// approvalsHandler handles GET /approvals
+func (s *Server) approvalsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	resp, err := s.grpcClient.ListApprovals(ctx)
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to list approvals")
+		return
+	}
+
+	httpResp := models.ApprovalsResponse{
+		Success:   true,
+		Approvals: []models.Approval{},
+	}
+	for _, a := range resp.Approvals {
+		httpResp.Approvals = append(httpResp.Approvals, *approvalFromMessage(a))
+	}
+
+	s.respondWithJSON(w, http.StatusOK, httpResp)
+}
+
+// approvalHandler handles GET and POST /approvals/{id}. A POST approves or
+// rejects the request as the caller's identity; the approval that completes
+// the required approvals waits for the command and returns its result.
+func (s *Server) approvalHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Anyone could claim to be an approver without authentication
+	if r.Method == http.MethodPost && s.keys == nil {
+		s.respondWithError(w, http.StatusForbidden, "approvals require authentication")
+		return
+	}
+
+	var decision models.ApprovalDecision
+	if r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize))
+		if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+			s.respondWithError(w, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+		if err := decision.Validate(); err != nil {
+			s.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Ensure we have a gRPC connection
+	if s.grpcClient == nil {
+		grpcClient, err := grpcclient.NewClient(s.config.SocketPath)
+		if err != nil {
+			s.respondWithError(w, http.StatusServiceUnavailable, "Daemon connection failed")
+			return
+		}
+		s.grpcClient = grpcClient
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.RequestTimeout)*time.Second)
+	defer cancel()
+
+	var resp *pb.ApprovalResponse
+	var err error
+	if r.Method == http.MethodPost {
+		resp, err = s.grpcClient.DecideApproval(ctx, r.PathValue("id"), s.daemonIdentity(r), &decision)
+	} else {
+		resp, err = s.grpcClient.GetApproval(ctx, r.PathValue("id"))
+	}
+	if err != nil {
+		s.respondWithError(w, http.StatusServiceUnavailable, "Failed to get approval")
+		return
+	}
+
+	status := http.StatusOK
+	switch resp.ErrorMessage {
+	case "":
+	case approval.ErrNotFound.Error():
+		status = http.StatusNotFound
+	case approval.ErrNoIdentity.Error(), approval.ErrRequester.Error(), approval.ErrNotApprover.Error():
+		status = http.StatusForbidden
+	case approval.ErrNotPending.Error(), approval.ErrExpired.Error(), approval.ErrAlreadyVoted.Error():
+		status = http.StatusConflict
+	default:
+		s.respondWithError(w, http.StatusInternalServerError, "Failed to decide approval")
+		return
+	}
+
+	s.respondWithJSON(w, status, models.ApprovalResponse{
+		Success:  resp.Success,
+		Approval: approvalFromMessage(resp.Approval),
+		Error:    resp.ErrorMessage,
+	})
+}
+
+// approvalFromMessage converts a daemon's approval, nil if there is none
+func approvalFromMessage(msg *pb.Approval) *models.Approval {
+	if msg == nil {
+		return nil
+	}
+
+	a := &models.Approval{
+		ID:        msg.Id,
+		Command:   msg.Command,
+		Args:      msg.Args,
+		Requester: msg.Requester,
+		Status:    msg.Status,
+		Required:  int(msg.Required),
+		Created:   msg.Created,
+		Expires:   msg.Expires,
+	}
+	for _, v := range msg.Votes {
+		a.Votes = append(a.Votes, models.ApprovalVote{
+			Identity: v.Identity,
+			Approve:  v.Approve,
+			Comment:  v.Comment,
+			Time:     v.Time,
+		})
+	}
+	if msg.Result != nil {
+		result := executeResponse(msg.Result)
+		a.Result = &result
+	}
+	return a
+}
+
+// identity returns the identity a request is made on behalf of, empty if
+// there is none. With authentication it is the name of the request's
+// caller.
+func (s *Server) identity(r *http.Request) string {
+	if s.keys != nil {
+		if principal := s.principal(r); principal != nil {
+			return principal.Name
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get(HeaderIdentity))
+}
+
+// daemonIdentity returns the identity passed to the daemon, which trusts it
+// for approvals and plans. Only authenticated callers have one: the
+// identity header is nothing more than a claim, so it is kept to the API's
+// log.
+func (s *Server) daemonIdentity(r *http.Request) string {
+	if s.keys == nil {
+		return ""
+	}
+	return s.identity(r)
+}
+
+// errCommandScope rejects a command outside the scope of a request's caller
+const errCommandScope = "command not allowed for this identity"
+
+// principal returns the caller a request was authenticated as, nil if
+// authentication isn't configured or failed
+func (s *Server) principal(r *http.Request) *models.Principal {
+	result, _ := r.Context().Value(authContextKey).(*authResult)
+	if result == nil {
+		return nil
+	}
+	return result.principal
+}
+
+// allowsCommand reports whether a request's caller may run a command
+func (s *Server) allowsCommand(r *http.Request, command string) bool {
+	principal := s.principal(r)
+	return principal == nil || principal.AllowsCommand(command)
+}
+
+// authMiddleware authenticates the API key, JWT or client certificate of
+// requests, if authentication is configured, for withAuth and the request
+// log
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	if s.keys == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var principal *models.Principal
+		var err error
+		authorization := r.Header.Get("Authorization")
+		switch {
+		case r.Header.Get(auth.HeaderSignature) != "":
+			principal, err = s.authenticateSigned(w, r)
+		case authorization == "" && r.TLS != nil && len(r.TLS.VerifiedChains) > 0:
+			// Without credentials in the request, the client certificate
+			// identifies the caller
+			principal, err = s.keys.AuthenticateCertificate(r.TLS.VerifiedChains[0][0])
+		default:
+			principal, err = s.keys.Authenticate(authorization)
+		}
+		ctx := context.WithValue(r.Context(), authContextKey, &authResult{principal: principal, err: err})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// authenticateSigned verifies the signature of a request, which covers its
+// body, and leaves the body to be read again
+func (s *Server) authenticateSigned(w http.ResponseWriter, r *http.Request) (*models.Principal, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(s.config.MaxBodySize)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unreadable body", auth.ErrInvalidSignature)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return s.keys.AuthenticateSigned(&auth.SignedRequest{
+		Method:    r.Method,
+		Path:      r.URL.RequestURI(),
+		Client:    r.Header.Get(auth.HeaderClient),
+		Timestamp: r.Header.Get(auth.HeaderTimestamp),
+		Nonce:     r.Header.Get(auth.HeaderNonce),
+		Signature: r.Header.Get(auth.HeaderSignature),
+		Body:      body,
+	})
+}
+
+// withAuth rejects requests without valid credentials, and those whose
+// caller isn't allowed the action. It does nothing if authentication isn't
+// configured.
+func (s *Server) withAuth(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.keys == nil {
+			next(w, r)
+			return
+		}
+
+		result, _ := r.Context().Value(authContextKey).(*authResult)
+		if result == nil || result.err != nil {
+			message := auth.ErrNoCredentials.Error()
+			if result != nil {
+				message = result.err.Error()
+			}
+			challenge := `Bearer realm="sevalet"`
+			if result != nil && errors.Is(result.err, auth.ErrInvalidToken) {
+				challenge += `, error="invalid_token"`
+			}
+			w.Header().Set("WWW-Authenticate", challenge)
+			s.respondWithError(w, http.StatusUnauthorized, message)
+			return
+		}
+		if !result.principal.Allows(action) {
+			s.respondWithError(w, http.StatusForbidden, "action not allowed for this identity")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// logDeliveryAttempt logs an attempt to deliver a completion webhook
+func (s *Server) logDeliveryAttempt(delivery models.Delivery, attempt models.DeliveryAttempt) {
+	logEntry := models.LogEntry{
+		Timestamp: attempt.Time,
+		Level:     "info",
+		Mode:      "api",
+		Event:     "webhook_attempt",
+		Delivery:  delivery.ID,
+		URL:       delivery.URL,
+		Attempt:   len(delivery.Attempts),
+		Status:    attempt.StatusCode,
+		Latency:   attempt.Duration,
+		Error:     attempt.Error,
+	}
+	switch delivery.Status {
+	case models.DeliveryPending:
+		logEntry.Level = "warn"
+	case models.DeliveryFailed:
+		logEntry.Level = "error"
+		logEntry.Event = "webhook_failed"
+	}
+	s.logJSON(logEntry)
+}
+
+// completionRetry is how long forwarding schedule completions waits after
+// failing to reach the daemon
+const completionRetry = 5 * time.Second
+
+// forwardScheduleCompletions sends the completion webhooks of the daemon's
+// scheduled runs until ctx is done, so that they are signed, retried and
+// listed like those of requests
+func (s *Server) forwardScheduleCompletions(ctx context.Context) {
+	defer close(s.completionsDone)
+
+	var client *grpcclient.Client
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	for {
+		var completions []*pb.ScheduleCompletion
+		var err error
+		if client == nil {
+			client, err = grpcclient.NewClient(s.config.SocketPath)
+		}
+		if err == nil {
+			completions, err = client.WaitScheduleCompletions(ctx)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to get schedule completions: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(completionRetry):
+			}
+			continue
+		}
+
+		for _, c := range completions {
+			s.dispatchScheduleCompletion(c)
+		}
+	}
+}
+
+// dispatchScheduleCompletion sends a schedule's completion webhook if the
+// webhooks allow its callback URL
+func (s *Server) dispatchScheduleCompletion(c *pb.ScheduleCompletion) {
+	if !s.webhooks.Allows(c.CallbackUrl) {
+		s.logJSON(models.LogEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Level:     "warn",
+			Mode:      "api",
+			Event:     "webhook_rejected",
+			Schedule:  c.Schedule,
+			URL:       c.CallbackUrl,
+			Error:     "callback_url is not in webhooks allowed_urls",
+		})
+		return
+	}
+
+	completed := models.CompletedSchedule{
+		Schedule: c.Schedule,
+		Started:  c.Started,
+		Duration: c.Duration,
+		Status:   c.Status,
+		Error:    c.Error,
+	}
+	s.webhooks.Dispatch("schedule.completed", c.CallbackUrl, func() any { return completed })
+}
+
+// logCertificateReload logs the reload of the TLS certificates
+func (s *Server) logCertificateReload(err error) {
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "api",
+		Event:     "tls_reloaded",
+	}
+	if err != nil {
+		logEntry.Level = "error"
+		logEntry.Event = "tls_reload_failed"
+		logEntry.Error = err.Error()
+	}
+	s.logJSON(logEntry)
+}
+
 // respondWithJSON sends a JSON response
 func (s *Server) respondWithJSON(w http.ResponseWriter, status int, payload interface{}) {
 	response, err := json.Marshal(payload)
@@ -271,6 +1308,7 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 			Method:     r.Method,
 			Path:       r.URL.Path,
 			RemoteAddr: r.RemoteAddr,
+			Identity:   s.identity(r),
 			Status:     wrapped.statusCode,
 			Latency:    latency.String(),
 		}
@@ -290,6 +1328,33 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// responseRecorder captures the response of a request run in the
+// background
+type responseRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{
+		header:     make(http.Header),
+		statusCode: http.StatusOK,
+	}
+}
+
+func (rr *responseRecorder) Header() http.Header {
+	return rr.header
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	return rr.body.Write(data)
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	rr.statusCode = code
+}
+
 // logJSON logs an entry in JSON format
 func (s *Server) logJSON(entry models.LogEntry) {
 	data, err := json.Marshal(entry)
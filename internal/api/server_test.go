@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/config"
+	"github.com/zinrai/sevalet/internal/idempotency"
+)
+
+func TestWithIdempotencyPanic(t *testing.T) {
+	s := &Server{
+		config:   &config.APIConfig{MaxBodySize: 1024},
+		requests: idempotency.New(time.Minute),
+	}
+
+	request := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/execute", strings.NewReader(`{"command":"uptime"}`))
+		r.Header.Set("Idempotency-Key", "retry-1")
+		return r
+	}
+
+	// The HTTP server recovers from the panic, the key must not stay in
+	// progress
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("handler didn't panic")
+			}
+		}()
+		s.withIdempotency(func(w http.ResponseWriter, r *http.Request) {
+			panic("lost daemon")
+		})(httptest.NewRecorder(), request())
+	}()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		w := httptest.NewRecorder()
+		s.withIdempotency(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})(w, request())
+		done <- w
+	}()
+
+	select {
+	case w := <-done:
+		if w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("retry = %d, replayed %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("retry after a panic still waits for the key")
+	}
+}
@@ -0,0 +1,229 @@
+// Package approval holds requests until enough approvers have approved them
+package approval
+
+import (
+	"crypto/rand"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// Errors returned by Create and Decide
+var (
+	ErrFull         = errors.New("too many requests awaiting approval")
+	ErrNotFound     = errors.New("approval not found")
+	ErrNotPending   = errors.New("approval is not pending")
+	ErrExpired      = errors.New("approval expired")
+	ErrNoIdentity   = errors.New("approval requires an authenticated identity")
+	ErrRequester    = errors.New("requester can't approve their own request")
+	ErrNotApprover  = errors.New("identity is not an approver for this command")
+	ErrAlreadyVoted = errors.New("identity already decided on this request")
+)
+
+// maxRecords bounds the requests kept, pending or decided. Finished
+// requests make room for new ones, pending and running ones are never
+// dropped.
+const maxRecords = 1000
+
+// Record is a request held for approval
+type Record struct {
+	models.Approval
+	Policy   models.ApprovalPolicy
+	Request  *pb.ExecuteRequest
+	Plan     *models.Plan        // The applied plan the request came from, if any
+	Response *pb.ExecuteResponse // Set once the request was executed
+
+	expires time.Time
+}
+
+// Store keeps the most recent requests held for approval
+type Store struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	order   []string
+	now     func() time.Time
+}
+
+// New creates an empty store
+func New() *Store {
+	return &Store{
+		records: make(map[string]*Record),
+		now:     time.Now,
+	}
+}
+
+// Create holds a request for approval under a policy. args are the
+// arguments shown to approvers, with secrets redacted. planned is the plan
+// the request was applied from, nil for other requests. When the store is
+// full, the oldest rejected, expired or executed request is dropped, and
+// ErrFull is returned if there is none.
+func (s *Store) Create(req *pb.ExecuteRequest, planned *models.Plan, args []string, requester string, policy models.ApprovalPolicy) (Record, error) {
+	if policy.Approvals == 0 {
+		policy.Approvals = 1
+	}
+	if policy.Expiry == 0 {
+		policy.Expiry = 3600
+	}
+
+	now := s.now()
+	r := &Record{
+		Approval: models.Approval{
+			ID:        rand.Text(),
+			Command:   req.Command,
+			Args:      args,
+			Requester: requester,
+			Status:    models.ApprovalPending,
+			Required:  policy.Approvals,
+			Created:   now.UTC().Format(time.RFC3339),
+		},
+		Policy:  policy,
+		Request: req,
+		Plan:    planned,
+		expires: now.Add(time.Duration(policy.Expiry) * time.Second),
+	}
+	r.Expires = r.expires.UTC().Format(time.RFC3339)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.order) >= maxRecords {
+		i := slices.IndexFunc(s.order, func(id string) bool {
+			old := s.records[id]
+			s.expire(old)
+			return old.Status != models.ApprovalPending && old.Status != models.ApprovalApproved
+		})
+		if i < 0 {
+			return Record{}, ErrFull
+		}
+		delete(s.records, s.order[i])
+		s.order = slices.Delete(s.order, i, i+1)
+	}
+
+	s.records[r.ID] = r
+	s.order = append(s.order, r.ID)
+
+	return r.copy(), nil
+}
+
+// Remove drops a record, for a request that couldn't be held after all
+func (s *Store) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[id]; ok {
+		delete(s.records, id)
+		s.order = slices.DeleteFunc(s.order, func(o string) bool { return o == id })
+	}
+}
+
+// Get returns a copy of a record
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[id]
+	if !ok {
+		return Record{}, false
+	}
+	s.expire(r)
+	return r.copy(), true
+}
+
+// List returns copies of the records, most recent first
+func (s *Store) List() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]Record, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		r := s.records[s.order[i]]
+		s.expire(r)
+		records = append(records, r.copy())
+	}
+	return records
+}
+
+// Decide records an approver's decision. A rejection rejects the request.
+// It reports whether the request has now been approved, in which case the
+// caller must execute it and call Complete. Only one caller is told so.
+func (s *Store) Decide(id, identity string, approve bool, comment string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[id]
+	if !ok {
+		return Record{}, false, ErrNotFound
+	}
+	if s.expire(r) {
+		return r.copy(), false, ErrExpired
+	}
+
+	switch {
+	case r.Status != models.ApprovalPending:
+		return r.copy(), false, ErrNotPending
+	case identity == "":
+		return r.copy(), false, ErrNoIdentity
+	case identity == r.Requester:
+		return r.copy(), false, ErrRequester
+	case len(r.Policy.Approvers) > 0 && !slices.Contains(r.Policy.Approvers, identity):
+		return r.copy(), false, ErrNotApprover
+	case slices.ContainsFunc(r.Votes, func(v models.ApprovalVote) bool { return v.Identity == identity }):
+		return r.copy(), false, ErrAlreadyVoted
+	}
+
+	r.Votes = append(r.Votes, models.ApprovalVote{
+		Identity: identity,
+		Approve:  approve,
+		Comment:  comment,
+		Time:     s.now().UTC().Format(time.RFC3339),
+	})
+
+	if !approve {
+		r.Status = models.ApprovalRejected
+		return r.copy(), false, nil
+	}
+
+	approvals := 0
+	for _, v := range r.Votes {
+		if v.Approve {
+			approvals++
+		}
+	}
+	if approvals < r.Required {
+		return r.copy(), false, nil
+	}
+
+	r.Status = models.ApprovalApproved
+	return r.copy(), true, nil
+}
+
+// Complete stores the response of an approved request
+func (s *Store) Complete(id string, resp *pb.ExecuteResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[id]; ok {
+		r.Status = models.ApprovalExecuted
+		r.Response = resp
+	}
+}
+
+// expire marks a pending record as expired if it is due, reporting
+// whether it is expired
+func (s *Store) expire(r *Record) bool {
+	if r.Status == models.ApprovalPending && s.now().After(r.expires) {
+		r.Status = models.ApprovalExpired
+	}
+	return r.Status == models.ApprovalExpired
+}
+
+// copy copies a record so it can be used outside the lock
+func (r *Record) copy() Record {
+	c := *r
+	c.Votes = slices.Clone(r.Votes)
+	return c
+}
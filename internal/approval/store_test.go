@@ -0,0 +1,174 @@
+package approval
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+func TestDecide(t *testing.T) {
+	type vote struct {
+		identity string
+		approve  bool
+		wantErr  error
+		ready    bool
+	}
+
+	tests := []struct {
+		name       string
+		policy     models.ApprovalPolicy
+		votes      []vote
+		wantStatus string
+	}{
+		{
+			name:       "single approval",
+			votes:      []vote{{identity: "bob", approve: true, ready: true}},
+			wantStatus: models.ApprovalApproved,
+		},
+		{
+			name:   "two approvals",
+			policy: models.ApprovalPolicy{Approvals: 2},
+			votes: []vote{
+				{identity: "bob", approve: true},
+				{identity: "bob", approve: true, wantErr: ErrAlreadyVoted},
+				{identity: "carol", approve: true, ready: true},
+				{identity: "dave", approve: true, wantErr: ErrNotPending},
+			},
+			wantStatus: models.ApprovalApproved,
+		},
+		{
+			name:       "requester can't approve",
+			votes:      []vote{{identity: "alice", approve: true, wantErr: ErrRequester}},
+			wantStatus: models.ApprovalPending,
+		},
+		{
+			name:       "identity required",
+			votes:      []vote{{identity: "", approve: true, wantErr: ErrNoIdentity}},
+			wantStatus: models.ApprovalPending,
+		},
+		{
+			name:   "listed approvers only",
+			policy: models.ApprovalPolicy{Approvers: []string{"carol"}},
+			votes: []vote{
+				{identity: "bob", approve: true, wantErr: ErrNotApprover},
+				{identity: "carol", approve: true, ready: true},
+			},
+			wantStatus: models.ApprovalApproved,
+		},
+		{
+			name:   "rejection",
+			policy: models.ApprovalPolicy{Approvals: 2},
+			votes: []vote{
+				{identity: "bob", approve: true},
+				{identity: "carol", approve: false},
+				{identity: "dave", approve: true, wantErr: ErrNotPending},
+			},
+			wantStatus: models.ApprovalRejected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			r, _ := s.Create(&pb.ExecuteRequest{Command: "systemctl", Args: []string{"stop", "postgresql"}}, nil, []string{"stop", "postgresql"}, "alice", tt.policy)
+
+			for i, v := range tt.votes {
+				_, ready, err := s.Decide(r.ID, v.identity, v.approve, "")
+				if !errors.Is(err, v.wantErr) || (err == nil && ready != v.ready) {
+					t.Fatalf("vote %d: Decide() = %v, %v, want %v, %v", i+1, ready, err, v.ready, v.wantErr)
+				}
+			}
+
+			got, _ := s.Get(r.ID)
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	r, _ := s.Create(&pb.ExecuteRequest{Command: "systemctl"}, nil, nil, "alice", models.ApprovalPolicy{Expiry: 60})
+	if r.Required != 1 || r.Expires != "2024-03-15T10:01:00Z" {
+		t.Errorf("Create() = required %d, expires %s", r.Required, r.Expires)
+	}
+
+	now = now.Add(61 * time.Second)
+	if _, _, err := s.Decide(r.ID, "bob", true, ""); !errors.Is(err, ErrExpired) {
+		t.Errorf("Decide() after expiry error = %v, want ErrExpired", err)
+	}
+	if got, _ := s.Get(r.ID); got.Status != models.ApprovalExpired {
+		t.Errorf("status = %s, want %s", got.Status, models.ApprovalExpired)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := New()
+	r, _ := s.Create(&pb.ExecuteRequest{Command: "systemctl"}, nil, nil, "alice", models.ApprovalPolicy{})
+	if _, _, err := s.Decide("nope", "bob", true, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Decide() for an unknown ID error = %v, want ErrNotFound", err)
+	}
+
+	s.Decide(r.ID, "bob", true, "")
+	s.Complete(r.ID, &pb.ExecuteResponse{Success: true})
+
+	got, _ := s.Get(r.ID)
+	if got.Status != models.ApprovalExecuted || got.Response == nil || !got.Response.Success {
+		t.Errorf("after Complete() status = %s, response = %v", got.Status, got.Response)
+	}
+	if list := s.List(); len(list) != 1 || list[0].ID != r.ID {
+		t.Errorf("List() = %v", list)
+	}
+}
+
+func TestFull(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	create := func(expiry int) (Record, error) {
+		return s.Create(&pb.ExecuteRequest{Command: "systemctl"}, nil, nil, "alice", models.ApprovalPolicy{Expiry: expiry})
+	}
+
+	var ids []string
+	for range maxRecords {
+		r, err := create(3600)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids = append(ids, r.ID)
+	}
+
+	// Pending requests are never dropped
+	if _, err := create(3600); !errors.Is(err, ErrFull) {
+		t.Fatalf("Create() with only pending requests error = %v, want ErrFull", err)
+	}
+
+	// A rejected request makes room, the older pending ones are kept
+	s.Decide(ids[1], "bob", false, "")
+	if _, err := create(60); err != nil {
+		t.Fatalf("Create() after a rejection error = %v", err)
+	}
+	if _, ok := s.Get(ids[1]); ok {
+		t.Error("rejected request was kept")
+	}
+	if _, ok := s.Get(ids[0]); !ok {
+		t.Error("oldest pending request was dropped")
+	}
+
+	// So does an expired one
+	now = now.Add(61 * time.Second)
+	if _, err := create(3600); err != nil {
+		t.Fatalf("Create() after an expiry error = %v", err)
+	}
+	if len(s.List()) != maxRecords {
+		t.Errorf("len(List()) = %d, want %d", len(s.List()), maxRecords)
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+	"slices"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// ErrUnknownCertificate is returned by AuthenticateCertificate for
+// certificates no rule matches
+var ErrUnknownCertificate = errors.New("client certificate not allowed")
+
+// AuthenticateCertificate returns the caller of a verified client
+// certificate by the first rule that matches it
+func (k *Keyring) AuthenticateCertificate(cert *x509.Certificate) (*models.Principal, error) {
+	for _, rule := range k.certificates {
+		if rule.CommonName != "" && rule.CommonName != cert.Subject.CommonName {
+			continue
+		}
+		if rule.SAN != "" && !slices.Contains(subjectAltNames(cert), rule.SAN) {
+			continue
+		}
+		return &models.Principal{Name: IdentityCertificate + rule.Name, Scope: rule.Scope}, nil
+	}
+	return nil, ErrUnknownCertificate
+}
+
+// subjectAltNames returns the DNS names, email addresses, URIs and IP
+// addresses of a certificate
+func subjectAltNames(cert *x509.Certificate) []string {
+	names := slices.Concat(cert.DNSNames, cert.EmailAddresses)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
+}
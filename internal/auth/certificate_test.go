@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestAuthenticateCertificate(t *testing.T) {
+	keyring, err := NewKeyring(&models.AuthConfig{Certificates: []models.ClientCertificate{
+		{Name: "deploy", SAN: "spiffe://example.com/deploy", Scope: models.Scope{Actions: []string{"execute"}}},
+		{Name: "monitoring", CommonName: "prometheus", SAN: "10.0.0.5", Scope: models.Scope{Actions: []string{"read"}}},
+		{Name: "ops", CommonName: "ops.example.com", Scope: models.Scope{Actions: []string{"*"}}},
+	}})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.com/deploy")
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		want    string
+		wantErr error
+	}{
+		{name: "URI", cert: &x509.Certificate{URIs: []*url.URL{spiffe}}, want: "cert:deploy"},
+		{
+			name: "common name and IP",
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "prometheus"}, IPAddresses: []net.IP{net.ParseIP("10.0.0.5")}},
+			want: "cert:monitoring",
+		},
+		{
+			name:    "common name without IP",
+			cert:    &x509.Certificate{Subject: pkix.Name{CommonName: "prometheus"}},
+			wantErr: ErrUnknownCertificate,
+		},
+		{name: "common name", cert: &x509.Certificate{Subject: pkix.Name{CommonName: "ops.example.com"}}, want: "cert:ops"},
+		{
+			name:    "DNS name isn't a common name",
+			cert:    &x509.Certificate{DNSNames: []string{"ops.example.com"}},
+			wantErr: ErrUnknownCertificate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := keyring.AuthenticateCertificate(tt.cert)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateCertificate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && principal.Name != tt.want {
+				t.Errorf("AuthenticateCertificate() = %s, want %s", principal.Name, tt.want)
+			}
+		})
+	}
+}
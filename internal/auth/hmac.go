@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// Headers of signed requests. The signature is "sha256=" followed by the
+// hex encoded HMAC-SHA256 of the method, the path, the timestamp, the nonce
+// and the body, each but the body followed by a newline.
+const (
+	HeaderClient    = "X-Sevalet-Client"
+	HeaderTimestamp = "X-Sevalet-Timestamp"
+	HeaderNonce     = "X-Sevalet-Nonce"
+	HeaderSignature = "X-Sevalet-Signature"
+)
+
+// Errors returned by Verify
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrReplayed         = errors.New("request already received")
+)
+
+// defaultMaxSkew is how far a timestamp may be off if the configuration
+// doesn't say
+const defaultMaxSkew = 5 * time.Minute
+
+// Nonces must be long enough to be unique, and short enough to keep
+const (
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+// SignedRequest is what a request's signature covers
+type SignedRequest struct {
+	Method    string
+	Path      string // Including the query, if any
+	Client    string
+	Timestamp string // Unix time in seconds
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+// Sign returns the hex encoded signature of a request
+func Sign(secret []byte, method, path, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n", method, path, timestamp, nonce)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// hmacClient is a client that signs its requests
+type hmacClient struct {
+	secret    []byte
+	principal models.Principal
+}
+
+// HMACVerifier verifies signed requests and rejects replays of them
+type HMACVerifier struct {
+	clients map[string]hmacClient
+	maxSkew time.Duration
+
+	mu sync.Mutex
+	// nonces holds the nonces of accepted requests by client until their
+	// timestamps are too old to be accepted again
+	nonces map[string]time.Time
+}
+
+// NewHMACVerifier creates a verifier from a validated configuration,
+// reading the secret files
+func NewHMACVerifier(config *models.HMACConfig) (*HMACVerifier, error) {
+	v := &HMACVerifier{
+		clients: make(map[string]hmacClient),
+		maxSkew: time.Duration(config.MaxSkew) * time.Second,
+		nonces:  make(map[string]time.Time),
+	}
+	if v.maxSkew == 0 {
+		v.maxSkew = defaultMaxSkew
+	}
+
+	for _, c := range config.Clients {
+		secret := []byte(c.Secret)
+		if c.SecretFile != "" {
+			data, err := os.ReadFile(c.SecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret of client %s: %w", c.Name, err)
+			}
+			secret = bytes.TrimSpace(data)
+			if len(secret) == 0 {
+				return nil, fmt.Errorf("secret file %s is empty", c.SecretFile)
+			}
+		}
+
+		// Signed requests are only accepted by /execute
+		v.clients[c.Name] = hmacClient{
+			secret: secret,
+			principal: models.Principal{
+				Name: IdentityHMAC + c.Name,
+				Scope: models.Scope{
+					Actions:  []string{models.ActionExecute},
+					Commands: c.Commands,
+				},
+			},
+		}
+	}
+
+	return v, nil
+}
+
+// Verify checks a request's signature and timestamp at now and that its
+// nonce wasn't used before, and returns its client
+func (v *HMACVerifier) Verify(req *SignedRequest, now time.Time) (*models.Principal, error) {
+	client, ok := v.clients[req.Client]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown client", ErrInvalidSignature)
+	}
+	if len(req.Nonce) < minNonceLength || len(req.Nonce) > maxNonceLength {
+		return nil, fmt.Errorf("%w: nonce must be %d to %d characters", ErrInvalidSignature, minNonceLength, maxNonceLength)
+	}
+	signature, ok := strings.CutPrefix(req.Signature, "sha256=")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	want := Sign(client.secret, req.Method, req.Path, req.Timestamp, req.Nonce, req.Body)
+	if !hmac.Equal([]byte(signature), []byte(want)) {
+		return nil, ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	timestamp := time.Unix(seconds, 0)
+	if timestamp.Before(now.Add(-v.maxSkew)) || timestamp.After(now.Add(v.maxSkew)) {
+		return nil, fmt.Errorf("%w: timestamp out of range", ErrInvalidSignature)
+	}
+
+	// The signature is checked first, so only valid requests take room
+	// in the cache
+	if !v.remember(req.Client+"\x00"+req.Nonce, timestamp.Add(v.maxSkew), now) {
+		return nil, ErrReplayed
+	}
+
+	principal := client.principal
+	return &principal, nil
+}
+
+// remember records a nonce until expires, reporting false if it is known
+func (v *HMACVerifier) remember(nonce string, expires, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for n, e := range v.nonces {
+		if e.Before(now) {
+			delete(v.nonces, n)
+		}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return false
+	}
+	v.nonces[nonce] = expires
+	return true
+}
+
+// AuthenticateSigned returns the client of a signed request
+func (k *Keyring) AuthenticateSigned(req *SignedRequest) (*models.Principal, error) {
+	if k.hmac == nil {
+		return nil, fmt.Errorf("%w: signed requests are not accepted", ErrInvalidSignature)
+	}
+	return k.hmac.Verify(req, time.Now())
+}
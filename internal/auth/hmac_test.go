@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestHMACVerifier(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "ci.key")
+	if err := os.WriteFile(secretFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewHMACVerifier(&models.HMACConfig{
+		MaxSkew: 60,
+		Clients: []models.HMACClient{
+			{Name: "ci", SecretFile: secretFile, Commands: []string{"uptime"}},
+			{Name: "legacy", Secret: "inline-secret", Commands: []string{"*"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHMACVerifier() error = %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"command":"uptime"}`)
+	signed := func(client, secret string, timestamp time.Time, nonce string) *SignedRequest {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return &SignedRequest{
+			Method:    "POST",
+			Path:      "/execute",
+			Client:    client,
+			Timestamp: ts,
+			Nonce:     nonce,
+			Signature: "sha256=" + Sign([]byte(secret), "POST", "/execute", ts, nonce, body),
+			Body:      body,
+		}
+	}
+	tampered := signed("ci", "file-secret", now, "tampered-nonce-01")
+	tampered.Body = []byte(`{"command":"reboot"}`)
+	otherPath := signed("ci", "file-secret", now, "other-path-nonce1")
+	otherPath.Path = "/pipeline"
+
+	tests := []struct {
+		name    string
+		req     *SignedRequest
+		want    string
+		wantErr error
+	}{
+		{name: "secret file", req: signed("ci", "file-secret", now, "0123456789abcdef"), want: "hmac:ci"},
+		{name: "inline secret", req: signed("legacy", "inline-secret", now, "0123456789abcdef"), want: "hmac:legacy"},
+		{name: "replayed", req: signed("ci", "file-secret", now, "0123456789abcdef"), wantErr: ErrReplayed},
+		{name: "within skew", req: signed("ci", "file-secret", now.Add(-time.Minute), "within-skew-nonce"), want: "hmac:ci"},
+		{name: "too old", req: signed("ci", "file-secret", now.Add(-61*time.Second), "too-old-nonce-001"), wantErr: ErrInvalidSignature},
+		{name: "in the future", req: signed("ci", "file-secret", now.Add(61*time.Second), "future-nonce-0001"), wantErr: ErrInvalidSignature},
+		{name: "wrong secret", req: signed("ci", "inline-secret", now, "wrong-secret-0001"), wantErr: ErrInvalidSignature},
+		{name: "unknown client", req: signed("nobody", "file-secret", now, "unknown-client-01"), wantErr: ErrInvalidSignature},
+		{name: "short nonce", req: signed("ci", "file-secret", now, "short"), wantErr: ErrInvalidSignature},
+		{name: "tampered body", req: tampered, wantErr: ErrInvalidSignature},
+		{name: "other path", req: otherPath, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.req, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if principal.Name != tt.want || !principal.Allows(models.ActionExecute) || principal.Allows(models.ActionRead) {
+				t.Errorf("Verify() = %+v, want %s allowed to execute only", principal, tt.want)
+			}
+		})
+	}
+
+	// Nonces are forgotten once their timestamp is too old anyway
+	later := now.Add(2 * time.Minute)
+	if _, err := verifier.Verify(signed("ci", "file-secret", later, "0123456789abcdef"), later); err != nil {
+		t.Errorf("Verify() of an expired nonce error = %v", err)
+	}
+	if len(verifier.nonces) != 1 {
+		t.Errorf("nonce cache holds %d nonces, want 1", len(verifier.nonces))
+	}
+}
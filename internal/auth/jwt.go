@@ -0,0 +1,477 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha512" // SHA-384 and SHA-512 signatures
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// ErrInvalidToken is returned by Verify for tokens that aren't accepted
+var ErrInvalidToken = errors.New("invalid token")
+
+// minRSABits is the smallest RSA key accepted for signatures
+const minRSABits = 2048
+
+// publicKey is a key tokens may be signed with
+type publicKey struct {
+	id  string // The JWK kid, empty for PEM keys
+	alg string // The JWK alg, empty if the key doesn't restrict it
+	key crypto.PublicKey
+}
+
+// Verifier verifies JWTs from an identity provider and grants their
+// callers scopes by their claims
+type Verifier struct {
+	config *models.JWTConfig
+	static []publicKey
+
+	mu       sync.Mutex
+	jwks     []publicKey
+	jwksTime time.Time // Modification time of the loaded JWKS file
+}
+
+// NewVerifier creates a verifier from a validated configuration, loading
+// its keys
+func NewVerifier(config *models.JWTConfig) (*Verifier, error) {
+	v := &Verifier{config: config}
+
+	for _, path := range config.PublicKeys {
+		key, err := loadPEMKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", path, err)
+		}
+		v.static = append(v.static, publicKey{key: key})
+	}
+
+	if config.JWKSFile != "" {
+		if err := v.loadJWKS(); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// Verify checks a token's signature, issuer, audience and validity period
+// at now, and returns its caller
+func (v *Verifier) Verify(token string, now time.Time) (*models.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if !v.verifySignature(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := v.validateClaims(claims, now); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	identityClaim := v.config.IdentityClaim
+	if identityClaim == "" {
+		identityClaim = "sub"
+	}
+	identity, _ := lookupClaim(claims, identityClaim).(string)
+	if identity == "" {
+		return nil, fmt.Errorf("%w: no %s claim", ErrInvalidToken, identityClaim)
+	}
+
+	principal := &models.Principal{Name: IdentityJWT + identity}
+	for _, rule := range v.config.Rules {
+		if slices.Contains(claimValues(lookupClaim(claims, rule.Claim)), rule.Value) {
+			principal.Scope.Add(rule.Scope)
+		}
+	}
+	return principal, nil
+}
+
+// validateClaims checks the registered claims
+func (v *Verifier) validateClaims(claims map[string]any, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+		return fmt.Errorf("wrong issuer")
+	}
+	if !hasAudience(claims["aud"], v.config.Audience) {
+		return fmt.Errorf("wrong audience")
+	}
+
+	skew := time.Duration(v.config.ClockSkew) * time.Second
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("no exp claim")
+	}
+	if !now.Before(time.Unix(int64(exp), 0).Add(skew)) {
+		return fmt.Errorf("expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(skew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("not valid yet")
+	}
+
+	return nil
+}
+
+// verifySignature reports whether one of the keys that may have signed
+// the token did
+func (v *Verifier) verifySignature(alg, kid string, input, signature []byte) bool {
+	keys := v.keys(kid)
+	if kid != "" && !slices.ContainsFunc(keys, func(k publicKey) bool { return k.id == kid }) {
+		// The provider may have rotated its keys
+		v.reloadJWKS()
+		keys = v.keys(kid)
+	}
+
+	for _, k := range keys {
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+		if verify(alg, k.key, input, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// keys returns the keys for a key ID: the JWKS keys with that ID and the
+// PEM keys, or all keys if there is no ID
+func (v *Verifier) keys(kid string) []publicKey {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	keys := slices.Clone(v.static)
+	for _, k := range v.jwks {
+		if kid == "" || k.id == "" || k.id == kid {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// reloadJWKS reads the JWKS file again if it changed. Errors are ignored,
+// keeping the loaded keys.
+func (v *Verifier) reloadJWKS() {
+	if v.config.JWKSFile == "" {
+		return
+	}
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		return
+	}
+
+	v.mu.Lock()
+	changed := !info.ModTime().Equal(v.jwksTime)
+	v.mu.Unlock()
+	if changed {
+		v.loadJWKS()
+	}
+}
+
+// loadJWKS reads the JWKS file
+func (v *Verifier) loadJWKS() error {
+	info, err := os.Stat(v.config.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	data, err := os.ReadFile(v.config.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("invalid JWKS file: %w", err)
+	}
+
+	v.mu.Lock()
+	v.jwks = keys
+	v.jwksTime = info.ModTime()
+	v.mu.Unlock()
+	return nil
+}
+
+// jwk is a JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the signing keys of a JSON Web Key Set. Keys of other
+// types, such as symmetric keys, are skipped.
+func parseJWKS(data []byte) ([]publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []publicKey
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i+1, err)
+		}
+		if key != nil {
+			keys = append(keys, publicKey{id: k.Kid, alg: k.Alg, key: key})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey returns the key, nil if it isn't a supported type
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid e")
+		}
+		key := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		return key, checkKey(key)
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid point: %w", err)
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+// loadPEMKey reads a public key or a certificate from a PEM file
+func loadPEMKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, checkKey(key)
+}
+
+// checkKey rejects key types and sizes that aren't supported
+func checkKey(key crypto.PublicKey) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSABits {
+			return fmt.Errorf("RSA key is shorter than %d bits", minRSABits)
+		}
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+// hashes maps the supported algorithms to their hash. Algorithms without a
+// public key, none and HMAC, are never accepted.
+var hashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// curves maps the ECDSA algorithms to their curve
+var curves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verify checks a signature made with an algorithm
+func verify(alg string, key crypto.PublicKey, input, signature []byte) bool {
+	if alg == "EdDSA" {
+		key, ok := key.(ed25519.PublicKey)
+		return ok && ed25519.Verify(key, input, signature)
+	}
+
+	hash, ok := hashes[alg]
+	if !ok {
+		return false
+	}
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if key.Curve != curves[alg] || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// decodeInt decodes a base64url encoded big-endian integer
+func decodeInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// lookupClaim returns a claim by its path of object keys separated by
+// dots, nil if there is none
+func lookupClaim(claims map[string]any, path string) any {
+	var value any = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// hasAudience reports whether an aud claim, a string or a list of strings,
+// names the audience. Unlike rule claims, a string isn't split into words.
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		return slices.Contains(aud, any(audience))
+	default:
+		return false
+	}
+}
+
+// claimValues returns the strings of a claim: the elements of a list, or
+// the words of a string
+func claimValues(claim any) []string {
+	switch claim := claim.(type) {
+	case string:
+		return strings.Fields(claim)
+	case []any:
+		var values []string
+		for _, v := range claim {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
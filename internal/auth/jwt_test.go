@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// signToken creates a JWT signed with key by alg
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	var err error
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(input))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS writes a JWKS file with an RSA key with the kid and an EC key
+func writeJWKS(t *testing.T, path, kid string, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) {
+	t.Helper()
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]any{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+			"n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec-1", "crv": "P-256",
+			"x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32))),
+		},
+		{"kty": "oct", "kid": "hmac", "k": encode([]byte("secret"))},
+	}}
+	data, _ := json.Marshal(set)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPublic, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	jwksFile := filepath.Join(dir, "jwks.json")
+	writeJWKS(t, jwksFile, "rsa-1", rsaKey, ecKey)
+	der, _ := x509.MarshalPKIXPublicKey(edPublic)
+	pemFile := filepath.Join(dir, "idp.pem")
+	if err := os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier, err := NewVerifier(&models.JWTConfig{
+		Issuer:     "https://idp.example.com",
+		Audience:   "sevalet",
+		JWKSFile:   jwksFile,
+		PublicKeys: []string{pemFile},
+		ClockSkew:  30,
+		Rules: []models.ClaimRule{
+			{Claim: "groups", Value: "sre", Scope: models.Scope{Actions: []string{"*"}, Commands: []string{"systemctl"}}},
+			{Claim: "scope", Value: "sevalet:read", Scope: models.Scope{Actions: []string{models.ActionRead}}},
+			{Claim: "realm_access.roles", Value: "diagnostics", Scope: models.Scope{Actions: []string{models.ActionExecute}, Commands: []string{"uptime"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": "https://idp.example.com",
+			"aud": "sevalet",
+			"sub": "alice",
+			"exp": now.Add(time.Minute).Unix(),
+		}
+		for k, v := range extra {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name         string
+		token        string
+		wantErr      bool
+		wantActions  []string
+		wantCommands []string
+	}{
+		{
+			name:         "RS256 with groups",
+			token:        signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"groups": []string{"dev", "sre"}})),
+			wantActions:  []string{"*"},
+			wantCommands: []string{"systemctl"},
+		},
+		{
+			name:        "ES256 with scope",
+			token:       signToken(t, "ES256", "ec-1", ecKey, claims(map[string]any{"scope": "openid sevalet:read", "aud": []string{"other", "sevalet"}})),
+			wantActions: []string{models.ActionRead},
+		},
+		{
+			name:         "EdDSA with nested roles",
+			token:        signToken(t, "EdDSA", "", edKey, claims(map[string]any{"realm_access": map[string]any{"roles": []string{"diagnostics"}}})),
+			wantActions:  []string{models.ActionExecute},
+			wantCommands: []string{"uptime"},
+		},
+		{name: "no matching rule", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(nil))},
+		{name: "within skew", token: signToken(t, "RS256", "", rsaKey, claims(map[string]any{"exp": now.Add(-20 * time.Second).Unix()}))},
+		{name: "expired", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})), wantErr: true},
+		{name: "no exp", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": nil})), wantErr: true},
+		{name: "not valid yet", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})), wantErr: true},
+		{name: "wrong issuer", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"iss": "https://evil"})), wantErr: true},
+		{name: "audience in a string of words", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"aud": "other sevalet"})), wantErr: true},
+		{name: "wrong audience", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"aud": "other"})), wantErr: true},
+		{name: "no subject", token: signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"sub": nil})), wantErr: true},
+		{name: "unknown key", token: signToken(t, "RS256", "rsa-1", otherKey, claims(nil)), wantErr: true},
+		{name: "wrong algorithm for key", token: signToken(t, "PS256", "rsa-1", rsaKey, claims(nil)), wantErr: true},
+		{name: "none", token: "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`)) + ".", wantErr: true},
+		{name: "malformed", token: "a.b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if principal.Name != "jwt:alice" || !slices.Equal(principal.Actions, tt.wantActions) || !slices.Equal(principal.Commands, tt.wantCommands) {
+				t.Errorf("Verify() = %+v, want actions %v and commands %v", principal, tt.wantActions, tt.wantCommands)
+			}
+		})
+	}
+}
+
+func TestVerifierReloadsJWKS(t *testing.T) {
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	writeJWKS(t, jwksFile, "rsa-1", rsaKey, ecKey)
+
+	verifier, err := NewVerifier(&models.JWTConfig{
+		Issuer:   "https://idp.example.com",
+		Audience: "sevalet",
+		JWKSFile: jwksFile,
+		Rules:    []models.ClaimRule{{Claim: "groups", Value: "sre", Scope: models.Scope{Actions: []string{"*"}}}},
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	// The provider rotates to a new key
+	now := time.Now()
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := signToken(t, "RS256", "rsa-2", rotated, map[string]any{
+		"iss": "https://idp.example.com", "aud": "sevalet", "sub": "bob", "exp": now.Add(time.Minute).Unix(),
+	})
+	if _, err := verifier.Verify(token, now); err == nil {
+		t.Fatal("Verify() accepted a key that isn't in the JWKS file")
+	}
+
+	writeJWKS(t, jwksFile, "rsa-2", rotated, ecKey)
+	later := now.Add(time.Second)
+	os.Chtimes(jwksFile, later, later)
+	if _, err := verifier.Verify(token, now); err != nil {
+		t.Errorf("Verify() with the rotated key error = %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package auth authenticates API requests
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Errors returned by Authenticate
+var (
+	ErrNoCredentials = errors.New("missing credentials")
+	ErrInvalidKey    = errors.New("invalid API key")
+)
+
+// Identities are prefixed by how the caller authenticated, so that a name
+// from one mechanism can't be taken through another, such as a JWT subject
+// that matches an API key's name
+const (
+	IdentityKey         = "key:"
+	IdentityJWT         = "jwt:"
+	IdentityCertificate = "cert:"
+	IdentityHMAC        = "hmac:"
+)
+
+// keyPrefix marks generated keys, which helps secret scanners find them
+const keyPrefix = "sevalet_"
+
+// GenerateKey returns a new random API key
+func GenerateKey() string {
+	return keyPrefix + strings.ToLower(rand.Text())
+}
+
+// HashKey returns the hash of an API key as stored in the configuration
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Keyring holds the API keys by hash, the JWT and signature verifiers if
+// JWTs and signed requests are accepted, and the client certificate rules
+type Keyring struct {
+	keys         map[string]*models.APIKey
+	jwt          *Verifier
+	hmac         *HMACVerifier
+	certificates []models.ClientCertificate
+}
+
+// NewKeyring creates a keyring from a validated configuration, loading the
+// keys file and JWT signing keys if there are any
+func NewKeyring(config *models.AuthConfig) (*Keyring, error) {
+	keys := config.Keys
+	if config.KeysFile != "" {
+		data, err := os.ReadFile(config.KeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keys file: %w", err)
+		}
+		var fileKeys []models.APIKey
+		if err := yaml.Unmarshal(data, &fileKeys); err != nil {
+			return nil, fmt.Errorf("failed to parse keys file: %w", err)
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if err := models.ValidateAPIKeys(keys); err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{
+		keys:         make(map[string]*models.APIKey),
+		certificates: config.Certificates,
+	}
+	for i := range keys {
+		k.keys[keys[i].Hash] = &keys[i]
+	}
+
+	if config.JWT != nil {
+		verifier, err := NewVerifier(config.JWT)
+		if err != nil {
+			return nil, err
+		}
+		k.jwt = verifier
+	}
+	if config.HMAC != nil {
+		verifier, err := NewHMACVerifier(config.HMAC)
+		if err != nil {
+			return nil, err
+		}
+		k.hmac = verifier
+	}
+
+	return k, nil
+}
+
+// Authenticate returns the caller of a request with an API key or a JWT in
+// its Authorization header as "Bearer <key or token>"
+func (k *Keyring) Authenticate(authorization string) (*models.Principal, error) {
+	scheme, credential, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	credential = strings.TrimSpace(credential)
+
+	// API keys have no dots, JWTs have two
+	if k.jwt != nil && strings.Count(credential, ".") == 2 {
+		return k.jwt.Verify(credential, time.Now())
+	}
+
+	// Keys are looked up by their hash, so the lookup reveals nothing
+	// about them
+	apiKey, ok := k.keys[HashKey(credential)]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return &models.Principal{Name: IdentityKey + apiKey.Name, Scope: apiKey.Scope}, nil
+}
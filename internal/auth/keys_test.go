@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestKeyring(t *testing.T) {
+	ciKey := GenerateKey()
+	opsKey := GenerateKey()
+	if !strings.HasPrefix(ciKey, "sevalet_") || ciKey == opsKey {
+		t.Fatalf("GenerateKey() = %q, %q", ciKey, opsKey)
+	}
+
+	keysFile := filepath.Join(t.TempDir(), "keys.yaml")
+	data := "- name: ops\n  hash: " + HashKey(opsKey) + "\n  actions: [\"*\"]\n"
+	if err := os.WriteFile(keysFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := NewKeyring(&models.AuthConfig{
+		Keys:     []models.APIKey{{Name: "ci", Hash: HashKey(ciKey), Scope: models.Scope{Actions: []string{"execute"}}}},
+		KeysFile: keysFile,
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          string
+		wantErr       error
+	}{
+		{name: "config key", authorization: "Bearer " + ciKey, want: "key:ci"},
+		{name: "file key", authorization: "bearer " + opsKey, want: "key:ops"},
+		{name: "missing", authorization: "", wantErr: ErrNoCredentials},
+		{name: "other scheme", authorization: "Basic " + ciKey, wantErr: ErrNoCredentials},
+		{name: "unknown key", authorization: "Bearer " + GenerateKey(), wantErr: ErrInvalidKey},
+		{name: "hash as key", authorization: "Bearer " + HashKey(ciKey), wantErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := keyring.Authenticate(tt.authorization)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && key.Name != tt.want {
+				t.Errorf("Authenticate() = %s, want %s", key.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeyringDuplicate(t *testing.T) {
+	hash := HashKey("k")
+	_, err := NewKeyring(&models.AuthConfig{Keys: []models.APIKey{
+		{Name: "a", Hash: hash, Scope: models.Scope{Actions: []string{"read"}}},
+		{Name: "b", Hash: hash, Scope: models.Scope{Actions: []string{"read"}}},
+	}})
+	if err == nil {
+		t.Error("NewKeyring() with two keys of the same hash succeeded")
+	}
+}
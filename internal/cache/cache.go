@@ -0,0 +1,114 @@
+// Package cache keeps results for a time to live and shares a single call
+// among concurrent callers asking for the same key
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache maps keys to values that expire
+type Cache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]*entry[V]
+	calls   map[string]*call[V]
+	now     func() time.Time
+}
+
+// entry is a stored value
+type entry[V any] struct {
+	value   V
+	stored  time.Time
+	expires time.Time
+}
+
+// call is a call in progress that other callers wait for
+type call[V any] struct {
+	done   chan struct{}
+	value  V
+	stored time.Time
+	// returned is false if the call panicked, kept if its value was stored
+	returned bool
+	kept     bool
+}
+
+// New creates an empty cache
+func New[V any]() *Cache[V] {
+	return &Cache[V]{
+		entries: make(map[string]*entry[V]),
+		calls:   make(map[string]*call[V]),
+		now:     time.Now,
+	}
+}
+
+// Do returns the value for key if it is stored and fresh. Otherwise it
+// calls fn, or waits for the call another caller already made for key. The
+// value fn returns is stored for ttl if keep is true. Do reports how old the
+// value is and whether it is cached: from the cache, or from another
+// caller's call whose value was stored. Values of other calls that weren't
+// stored are shared as well, but reported as not cached. If the call that
+// was waited for panics, Do calls fn itself.
+func (c *Cache[V]) Do(key string, ttl time.Duration, fn func() (value V, keep bool)) (V, time.Duration, bool) {
+	for {
+		c.mu.Lock()
+		now := c.now()
+		c.sweep(now)
+
+		if e, ok := c.entries[key]; ok {
+			c.mu.Unlock()
+			return e.value, now.Sub(e.stored), true
+		}
+		cl, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+
+		<-cl.done
+		if cl.kept {
+			return cl.value, c.now().Sub(cl.stored), true
+		}
+		if cl.returned {
+			return cl.value, 0, false
+		}
+	}
+
+	cl := &call[V]{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	// Waiters are released even if fn panics
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+
+	value, keep := fn()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl.value = value
+	cl.stored = c.now()
+	cl.returned = true
+	if keep && ttl > 0 {
+		cl.kept = true
+		c.entries[key] = &entry[V]{
+			value:   value,
+			stored:  cl.stored,
+			expires: cl.stored.Add(ttl),
+		}
+	}
+
+	return value, 0, false
+}
+
+// sweep removes expired entries
+func (c *Cache[V]) sweep(now time.Time) {
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
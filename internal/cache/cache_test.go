@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	c := New[string]()
+	c.now = func() time.Time { return now }
+
+	var calls int
+	fn := func() (string, bool) {
+		calls++
+		return "up 3 days", true
+	}
+
+	if v, age, cached := c.Do("uptime", 5*time.Second, fn); v != "up 3 days" || age != 0 || cached {
+		t.Fatalf("first Do() = %q, %v, %v", v, age, cached)
+	}
+
+	now = now.Add(2 * time.Second)
+	if v, age, cached := c.Do("uptime", 5*time.Second, fn); v != "up 3 days" || age != 2*time.Second || !cached {
+		t.Errorf("Do() within the TTL = %q, %v, %v", v, age, cached)
+	}
+
+	now = now.Add(3 * time.Second)
+	if _, _, cached := c.Do("uptime", 5*time.Second, fn); cached {
+		t.Error("Do() after the TTL returned a cached value")
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2", calls)
+	}
+}
+
+func TestDoNotKept(t *testing.T) {
+	c := New[int]()
+
+	var calls int
+	fn := func() (int, bool) {
+		calls++
+		return calls, false
+	}
+
+	c.Do("df", time.Minute, fn)
+	if v, _, cached := c.Do("df", time.Minute, fn); v != 2 || cached {
+		t.Errorf("Do() after a value that wasn't kept = %d, %v", v, cached)
+	}
+}
+
+func TestDoSharesConcurrentCalls(t *testing.T) {
+	c := New[int]()
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func() (int, bool) {
+		calls.Add(1)
+		<-release
+		return 42, true
+	}
+
+	// The first caller runs fn, the others wait for it
+	started := make(chan struct{})
+	go func() {
+		c.Do("free", time.Minute, func() (int, bool) {
+			close(started)
+			return fn()
+		})
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, _, cached := c.Do("free", time.Minute, fn); v == 42 && cached {
+				shared.Add(1)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fn called %d times, want 1", calls.Load())
+	}
+	if shared.Load() != 5 {
+		t.Errorf("%d callers shared the call, want 5", shared.Load())
+	}
+}
+
+func TestDoSharedNotKept(t *testing.T) {
+	c := New[int]()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go c.Do("df", time.Minute, func() (int, bool) {
+		close(started)
+		<-release
+		return 1, false
+	})
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, _, cached := c.Do("df", time.Minute, func() (int, bool) { return 2, true }); v != 1 || cached {
+			t.Errorf("Do() sharing a value that wasn't kept = %d, %v", v, cached)
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-done
+}
+
+func TestDoPanic(t *testing.T) {
+	c := New[int]()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		c.Do("df", time.Minute, func() (int, bool) {
+			close(started)
+			<-release
+			panic("failed")
+		})
+	}()
+	<-started
+
+	// A waiter runs fn itself once the call panicked
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if v, _, cached := c.Do("df", time.Minute, func() (int, bool) { return 2, true }); v != 2 || cached {
+			t.Errorf("Do() after a panic = %d, %v", v, cached)
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Do() still waits for a call that panicked")
+	}
+
+	if v, _, cached := c.Do("df", time.Minute, func() (int, bool) { return 3, true }); v != 2 || !cached {
+		t.Errorf("Do() after the retry = %d, %v", v, cached)
+	}
+}
@@ -0,0 +1,159 @@
+// Package certs provides TLS configurations whose certificates are reloaded
+// when their files change
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// checkInterval is how often the files are checked for changes
+const checkInterval = time.Second
+
+// ReloadFunc is called after the files changed, with the error if they
+// couldn't be loaded. The previous certificates are then kept.
+type ReloadFunc func(err error)
+
+// Reloader holds the TLS configuration loaded from the files
+type Reloader struct {
+	config   *models.TLSConfig
+	onReload ReloadFunc
+
+	mu       sync.Mutex
+	current  *tls.Config
+	modTimes []time.Time
+	checked  time.Time
+	now      func() time.Time
+}
+
+// New loads the files of a validated configuration
+func New(config *models.TLSConfig, onReload ReloadFunc) (*Reloader, error) {
+	r := &Reloader{
+		config:   config,
+		onReload: onReload,
+		now:      time.Now,
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	current, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current = current
+	r.modTimes = modTimes
+	r.checked = r.now()
+
+	return r, nil
+}
+
+// TLSConfig returns a configuration for a server that uses the current
+// certificates for each connection. The configuration for a connection
+// replaces the server's, so it gets the server's protocols for ALPN, HTTP/2
+// and HTTP/1.1, and session ticket settings.
+func (r *Reloader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := r.Config().Clone()
+		c.NextProtos = config.NextProtos
+		c.SessionTicketsDisabled = config.SessionTicketsDisabled
+		return c, nil
+	}
+	return config
+}
+
+// Config returns the configuration with the current certificates, loading
+// them again if the files changed
+func (r *Reloader) Config() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checked) < checkInterval {
+		return r.current
+	}
+	r.checked = now
+
+	// Files missing while they are replaced are checked again later
+	modTimes, err := r.stat()
+	if err != nil || slices.EqualFunc(modTimes, r.modTimes, time.Time.Equal) {
+		return r.current
+	}
+
+	current, err := r.load()
+	if err == nil {
+		r.current = current
+	}
+	// A broken file isn't retried until it changes again
+	r.modTimes = modTimes
+	if r.onReload != nil {
+		r.onReload(err)
+	}
+	return r.current
+}
+
+// files returns the files of the configuration
+func (r *Reloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+// stat returns the modification times of the files
+func (r *Reloader) stat() ([]time.Time, error) {
+	var modTimes []time.Time
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+// load reads the certificate, its key and the client CAs
+func (r *Reloader) load() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if r.config.ClientCAFile == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(r.config.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in client CA file")
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if r.config.RequireClientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
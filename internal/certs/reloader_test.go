@@ -0,0 +1,135 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// writeCert writes a self-signed certificate for a common name and its key
+func writeCert(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	t.Helper()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	os.Chtimes(certFile, modTime, modTime)
+	os.Chtimes(keyFile, modTime, modTime)
+}
+
+// commonName returns the common name of a configuration's certificate
+func commonName(t *testing.T, config *tls.Config) string {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "api.crt")
+	keyFile := filepath.Join(dir, "api.key")
+	start := time.Now().Add(-time.Minute)
+	writeCert(t, certFile, keyFile, "first", start)
+
+	var reloads []error
+	r, err := New(&models.TLSConfig{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: certFile,
+	}, func(err error) { reloads = append(reloads, err) })
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	config := r.Config()
+	if commonName(t, config) != "first" || config.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Fatalf("Config() = %s, %v", commonName(t, config), config.ClientAuth)
+	}
+
+	// Changes are picked up after the check interval
+	writeCert(t, certFile, keyFile, "second", start.Add(time.Second))
+	if commonName(t, r.Config()) != "first" {
+		t.Error("Config() reloaded before the check interval")
+	}
+	now = now.Add(checkInterval)
+	if commonName(t, r.Config()) != "second" || len(reloads) != 1 || reloads[0] != nil {
+		t.Errorf("Config() didn't reload, reloads = %v", reloads)
+	}
+
+	// A broken key keeps the previous certificate
+	os.WriteFile(keyFile, []byte("broken"), 0600)
+	now = now.Add(checkInterval)
+	if commonName(t, r.Config()) != "second" || len(reloads) != 2 || reloads[1] == nil {
+		t.Errorf("Config() with a broken key, reloads = %v", reloads)
+	}
+	now = now.Add(checkInterval)
+	r.Config()
+	if len(reloads) != 2 {
+		t.Errorf("Config() retried an unchanged broken file, reloads = %v", reloads)
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "api.crt")
+	keyFile := filepath.Join(dir, "api.key")
+	writeCert(t, certFile, keyFile, "api", time.Now())
+
+	r, err := New(&models.TLSConfig{CertFile: certFile, KeyFile: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	server := r.TLSConfig()
+	server.SessionTicketsDisabled = true
+	config, err := server.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("GetConfigForClient() error = %v", err)
+	}
+	if commonName(t, config) != "api" || !slices.Equal(config.NextProtos, []string{"h2", "http/1.1"}) || !config.SessionTicketsDisabled {
+		t.Errorf("GetConfigForClient() = %s, %v, %v", commonName(t, config), config.NextProtos, config.SessionTicketsDisabled)
+	}
+}
+
+func TestNewMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := New(&models.TLSConfig{
+		CertFile: filepath.Join(dir, "api.crt"),
+		KeyFile:  filepath.Join(dir, "api.key"),
+	}, nil)
+	if err == nil {
+		t.Error("New() without certificate files succeeded")
+	}
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/validator"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,17 +16,34 @@ type DaemonConfig struct {
 	SocketPermissions string             `yaml:"socket_permissions"`
 	MaxExecutionTime  int                `yaml:"max_execution_time"`
 	DefaultTimeout    int                `yaml:"default_timeout"`
+	CgroupRoot        string             `yaml:"cgroup_root"`
+	DefaultNice       *int               `yaml:"default_nice"`
+	DefaultIONice     *models.IOPriority `yaml:"default_ionice"`
+	Redact            *models.Redaction  `yaml:"redact"`
+	Runbooks          []models.Runbook   `yaml:"runbooks"`
+	Schedules         []models.Schedule  `yaml:"schedules"`
+	PlanTTL           int                `yaml:"plan_ttl"` // Seconds a plan token can be applied for
+	Windows           []models.Window    `yaml:"windows"`
+	Freezes           []models.Freeze    `yaml:"freezes"`
 	Commands          models.CommandList `yaml:",inline"`
 	LogLevel          string             `yaml:"-"` // Set via command line only
 }
 
 // APIConfig represents the API mode configuration
 type APIConfig struct {
-	ListenAddress  string `yaml:"listen_address"`
-	SocketPath     string `yaml:"socket_path"`
-	RequestTimeout int    `yaml:"request_timeout"`
-	MaxBodySize    int    `yaml:"max_body_size"`
-	LogLevel       string `yaml:"-"` // Set via command line only
+	ListenAddress  string                `yaml:"listen_address"`
+	SocketPath     string                `yaml:"socket_path"`
+	RequestTimeout int                   `yaml:"request_timeout"`
+	MaxBodySize    int                   `yaml:"max_body_size"`
+	Webhooks       *models.WebhookConfig `yaml:"webhooks"`
+	// IdempotencyTTL is how long responses are kept for their
+	// Idempotency-Key in seconds
+	IdempotencyTTL int `yaml:"idempotency_ttl"`
+	// Auth requires API keys, every request is allowed if it is nil
+	Auth *models.AuthConfig `yaml:"auth"`
+	// TLS serves the API over HTTPS instead of HTTP
+	TLS      *models.TLSConfig `yaml:"tls"`
+	LogLevel string            `yaml:"-"` // Set via command line only
 }
 
 // LoadDaemonConfig loads the daemon configuration from a YAML file
@@ -52,11 +71,156 @@ func LoadDaemonConfig(path string) (*DaemonConfig, error) {
 	if config.DefaultTimeout <= 0 {
 		config.DefaultTimeout = 30
 	}
+	if config.PlanTTL <= 0 {
+		config.PlanTTL = 300
+	}
+
+	// Validate scheduling defaults
+	if err := models.ValidateNice(config.DefaultNice); err != nil {
+		return nil, fmt.Errorf("invalid default_nice: %w", err)
+	}
+	if err := config.DefaultIONice.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid default_ionice: %w", err)
+	}
+
+	// Validate global redaction rules
+	if err := config.Redact.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid redact: %w", err)
+	}
+
+	// Validate maintenance windows and change freezes
+	var windowNames, freezeNames []string
+	for _, window := range config.Windows {
+		if err := window.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid window %s: %w", window.Name, err)
+		}
+		if slices.Contains(windowNames, window.Name) {
+			return nil, fmt.Errorf("duplicate window %s", window.Name)
+		}
+		windowNames = append(windowNames, window.Name)
+	}
+	for _, freeze := range config.Freezes {
+		if err := freeze.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid freeze %s: %w", freeze.Name, err)
+		}
+		if slices.Contains(freezeNames, freeze.Name) {
+			return nil, fmt.Errorf("duplicate freeze %s", freeze.Name)
+		}
+		freezeNames = append(freezeNames, freeze.Name)
+	}
 
 	// Validate commands
 	if len(config.Commands.Commands) == 0 {
 		return nil, fmt.Errorf("no commands defined in configuration")
 	}
+	for _, cmd := range config.Commands.Commands {
+		if err := cmd.Limits.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid limits for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Cgroup.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cgroup for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Sandbox.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid sandbox for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Seccomp.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid seccomp for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Landlock.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid landlock for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Capabilities.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid capabilities for command %s: %w", cmd.Name, err)
+		}
+		if err := models.ValidateNice(cmd.Nice); err != nil {
+			return nil, fmt.Errorf("invalid nice for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.IONice.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid ionice for command %s: %w", cmd.Name, err)
+		}
+		if err := models.ValidateOutputMode(cmd.OutputMode); err != nil {
+			return nil, fmt.Errorf("invalid output_mode for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Output.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid output parser for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Redact.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid redact for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.Filter.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid filter for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.ValidateSuccess(); err != nil {
+			return nil, fmt.Errorf("invalid success criteria for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.ValidateCache(); err != nil {
+			return nil, fmt.Errorf("invalid cache for command %s: %w", cmd.Name, err)
+		}
+		if err := cmd.RequiresApproval.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid requires_approval for command %s: %w", cmd.Name, err)
+		}
+		if cmd.Window != "" && !slices.Contains(windowNames, cmd.Window) {
+			return nil, fmt.Errorf("command %s refers to undefined window %s", cmd.Name, cmd.Window)
+		}
+		for _, freeze := range cmd.Freezes {
+			if !slices.Contains(freezeNames, freeze) {
+				return nil, fmt.Errorf("command %s refers to undefined freeze %s", cmd.Name, freeze)
+			}
+		}
+		if cmd.Cgroup != nil && config.CgroupRoot == "" {
+			return nil, fmt.Errorf("command %s sets cgroup limits but cgroup_root is not configured", cmd.Name)
+		}
+	}
+
+	// Validate runbooks
+	var runbookNames []string
+	for _, runbook := range config.Runbooks {
+		if err := runbook.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid runbook %s: %w", runbook.Name, err)
+		}
+		if slices.Contains(runbookNames, runbook.Name) {
+			return nil, fmt.Errorf("duplicate runbook %s", runbook.Name)
+		}
+		runbookNames = append(runbookNames, runbook.Name)
+
+		for _, step := range runbook.Steps {
+			for _, s := range append([]models.RunbookStep{step}, step.OnFailure...) {
+				if err := validator.ValidateCommand(s.Command, s.Args, &config.Commands); err != nil {
+					return nil, fmt.Errorf("invalid runbook %s: step %s: %w", runbook.Name, s.Name, err)
+				}
+				// Steps aren't held for approval, so they can't use commands
+				// that require it
+				if cmd := config.Commands.FindCommand(s.Command); cmd != nil && cmd.RequiresApproval != nil {
+					return nil, fmt.Errorf("invalid runbook %s: step %s: command %s requires approval", runbook.Name, s.Name, s.Command)
+				}
+			}
+		}
+	}
+
+	// Validate schedules
+	var scheduleNames []string
+	for _, schedule := range config.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", schedule.Name, err)
+		}
+		if slices.Contains(scheduleNames, schedule.Name) {
+			return nil, fmt.Errorf("duplicate schedule %s", schedule.Name)
+		}
+		scheduleNames = append(scheduleNames, schedule.Name)
+
+		if schedule.Runbook != "" {
+			if !slices.Contains(runbookNames, schedule.Runbook) {
+				return nil, fmt.Errorf("invalid schedule %s: runbook %s is not defined", schedule.Name, schedule.Runbook)
+			}
+			continue
+		}
+		if err := validator.ValidateCommand(schedule.Command, schedule.Args, &config.Commands); err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", schedule.Name, err)
+		}
+		if cmd := config.Commands.FindCommand(schedule.Command); cmd != nil && cmd.RequiresApproval != nil {
+			return nil, fmt.Errorf("invalid schedule %s: command %s requires approval", schedule.Name, schedule.Command)
+		}
+	}
 
 	return &config, nil
 }
@@ -86,6 +250,27 @@ func LoadAPIConfig(path string) (*APIConfig, error) {
 	if config.MaxBodySize <= 0 {
 		config.MaxBodySize = 1048576 // 1MB
 	}
+	if config.IdempotencyTTL <= 0 {
+		config.IdempotencyTTL = 86400 // 24 hours
+	}
+
+	// Validate webhooks
+	if err := config.Webhooks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid webhooks: %w", err)
+	}
+
+	// Validate authentication
+	if err := config.Auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth: %w", err)
+	}
+
+	// Validate TLS
+	if err := config.TLS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tls: %w", err)
+	}
+	if config.Auth != nil && len(config.Auth.Certificates) > 0 && (config.TLS == nil || config.TLS.ClientCAFile == "") {
+		return nil, fmt.Errorf("invalid auth: certificates need tls client_ca_file")
+	}
 
 	return &config, nil
 }
@@ -0,0 +1,92 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "every minute", expr: "* * * * *"},
+		{name: "lists ranges and steps", expr: "0,30 9-17/2 1-15 */3 mon-fri"},
+		{name: "names", expr: "0 0 * JAN,jul sun"},
+		{name: "sunday as 7", expr: "0 0 * * 7"},
+		{name: "macro", expr: "@daily"},
+		{name: "value with step", expr: "5/15 * * * *"},
+		{name: "too few fields", expr: "* * * *", wantErr: true},
+		{name: "too many fields", expr: "* * * * * *", wantErr: true},
+		{name: "minute out of range", expr: "60 * * * *", wantErr: true},
+		{name: "day of month zero", expr: "0 0 0 * *", wantErr: true},
+		{name: "reversed range", expr: "0 17-9 * * *", wantErr: true},
+		{name: "zero step", expr: "*/0 * * * *", wantErr: true},
+		{name: "unknown name", expr: "0 0 * foo *", wantErr: true},
+		{name: "unknown macro", expr: "@often", wantErr: true},
+		{name: "empty", expr: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2024-03-15 is a Friday
+	from := time.Date(2024, 3, 15, 10, 17, 42, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{name: "every minute", expr: "* * * * *", from: from, want: time.Date(2024, 3, 15, 10, 18, 0, 0, time.UTC)},
+		{name: "step minutes", expr: "*/15 * * * *", from: from, want: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{name: "exact time is not repeated", expr: "30 10 * * *", from: time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC), want: time.Date(2024, 3, 16, 10, 30, 0, 0, time.UTC)},
+		{name: "hourly", expr: "@hourly", from: from, want: time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{name: "daily rolls over", expr: "@daily", from: from, want: time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "weekdays skip the weekend", expr: "0 9 * * mon-fri", from: from, want: time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)},
+		{name: "sunday as 7", expr: "0 0 * * 7", from: from, want: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "month rolls over the year", expr: "0 0 1 jan *", from: from, want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expr: "0 0 29 2 *", from: from, want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "either day field", expr: "0 0 1 * sun", from: from, want: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{name: "never", expr: "0 0 31 2 *", from: from, want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	// 02:30 doesn't exist on 2024-03-10 in New York
+	s, err := Parse("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Next(time.Date(2024, 3, 10, 0, 0, 0, 0, loc))
+	if !got.After(time.Date(2024, 3, 10, 0, 0, 0, 0, loc)) {
+		t.Errorf("Next() = %v, want a later time", got)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/zinrai/sevalet/internal/config"
+	"github.com/zinrai/sevalet/internal/executor"
 	grpcsrv "github.com/zinrai/sevalet/internal/grpc"
 	"github.com/zinrai/sevalet/pb"
 	"google.golang.org/grpc"
@@ -19,6 +22,7 @@ import (
 type Daemon struct {
 	config     *config.DaemonConfig
 	grpcServer *grpc.Server
+	service    *grpcsrv.Server
 	listener   net.Listener
 }
 
@@ -31,6 +35,25 @@ func New(config *config.DaemonConfig) *Daemon {
 
 // Start starts the daemon and listens for requests
 func (d *Daemon) Start() error {
+	// Prepare the delegated cgroup subtree for per-execution cgroups
+	if d.config.CgroupRoot != "" {
+		missing, err := executor.SetupCgroupRoot(d.config.CgroupRoot)
+		if err != nil {
+			return fmt.Errorf("failed to set up cgroup root: %w", err)
+		}
+		for _, cmd := range d.config.Commands.Commands {
+			for _, controller := range cmd.Cgroup.Controllers() {
+				if slices.Contains(missing, controller) {
+					return fmt.Errorf("cgroup controller %s needed by command %s is not available in %s", controller, cmd.Name, d.config.CgroupRoot)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			log.Printf("WARNING: cgroup controllers not available in %s: %s", d.config.CgroupRoot, strings.Join(missing, ", "))
+		}
+		log.Printf("Executions are placed in cgroups under %s", d.config.CgroupRoot)
+	}
+
 	// Remove existing socket file if it exists
 	if err := os.Remove(d.config.SocketPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove existing socket: %w", err)
@@ -61,9 +84,19 @@ func (d *Daemon) Start() error {
 	)
 
 	// Register gRPC service
-	grpcService := grpcsrv.NewServer(d.config)
+	grpcService, err := grpcsrv.NewServer(d.config)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC service: %w", err)
+	}
+	d.service = grpcService
 	pb.RegisterCommandExecutorServer(d.grpcServer, grpcService)
 
+	// Start schedules
+	if len(d.config.Schedules) > 0 {
+		grpcService.StartSchedules()
+		log.Printf("Started %d schedules", len(d.config.Schedules))
+	}
+
 	// Setup signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -91,6 +124,11 @@ func (d *Daemon) Start() error {
 func (d *Daemon) Shutdown() error {
 	log.Println("Shutting down daemon...")
 
+	// Stop schedules, cancelling runs in progress, and webhook retries
+	if d.service != nil {
+		d.service.Close()
+	}
+
 	// Stop accepting new connections
 	if d.grpcServer != nil {
 		d.grpcServer.GracefulStop()
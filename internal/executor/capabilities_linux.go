@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"golang.org/x/sys/unix"
+)
+
+// applyCapabilities drops every capability not listed from the bounding set
+// and raises the ambient ones, so the command ends up with only those
+func applyCapabilities(c *models.Capabilities) error {
+	if c == nil {
+		return nil
+	}
+
+	keep := make(map[int]bool)
+	var ambient []int
+	for _, name := range c.Bounding {
+		nr, _ := models.CapabilityNumber(name)
+		keep[nr] = true
+	}
+	for _, name := range c.Ambient {
+		nr, _ := models.CapabilityNumber(name)
+		keep[nr] = true
+		ambient = append(ambient, nr)
+	}
+
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		return &os.SyscallError{Syscall: "capget", Err: err}
+	}
+
+	if data[0].Effective&(1<<unix.CAP_SETPCAP) != 0 {
+		for nr := 0; nr <= lastCapability(); nr++ {
+			if keep[nr] {
+				continue
+			}
+			if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(nr), 0, 0, 0); err != nil {
+				return &os.SyscallError{Syscall: "prctl PR_CAPBSET_DROP", Err: err}
+			}
+		}
+	} else {
+		// Without CAP_SETPCAP the bounding set can't be changed, but
+		// no_new_privs equally keeps the command from gaining capabilities
+		// beyond the ambient ones through setuid or file capabilities
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			return &os.SyscallError{Syscall: "prctl PR_SET_NO_NEW_PRIVS", Err: err}
+		}
+	}
+
+	if len(ambient) == 0 {
+		return nil
+	}
+
+	// Ambient capabilities must be both permitted and inheritable
+	for _, nr := range ambient {
+		data[nr/32].Inheritable |= 1 << (nr % 32)
+	}
+	if err := unix.Capset(&hdr, &data[0]); err != nil {
+		return fmt.Errorf("failed to make capabilities inheritable, the daemon must hold them: %w", err)
+	}
+
+	for _, nr := range ambient {
+		if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, uintptr(nr), 0, 0); err != nil {
+			return &os.SyscallError{Syscall: "prctl PR_CAP_AMBIENT_RAISE", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// lastCapability returns the highest capability number known to the kernel
+func lastCapability() int {
+	data, err := os.ReadFile("/proc/sys/kernel/cap_last_cap")
+	if err == nil {
+		if nr, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return nr
+		}
+	}
+	return unix.CAP_LAST_CAP
+}
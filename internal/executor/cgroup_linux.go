@@ -0,0 +1,290 @@
+package executor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// cgroupControllers are enabled for the execution cgroups under the root
+var cgroupControllers = []string{"memory", "cpu", "pids"}
+
+// cgroupMount is where the cgroup v2 hierarchy is mounted
+const cgroupMount = "/sys/fs/cgroup"
+
+// daemonCgroup is the leaf the daemon moves to when the root is inside its
+// own cgroup
+const daemonCgroup = "daemon"
+
+// cgroupSeq makes execution cgroup names unique within the daemon
+var cgroupSeq atomic.Uint64
+
+// cgroup is the cgroup v2 directory created for a single execution
+type cgroup struct {
+	path string
+	dir  *os.File
+}
+
+// SetupCgroupRoot creates the delegated cgroup root and enables the
+// controllers used by the per-command limits for its children. It returns
+// the controllers that are not available in the root.
+//
+// Controllers can only be enabled for the children of a cgroup without
+// processes of its own. When the root is inside the daemon's own cgroup,
+// such as a directory of a systemd service with Delegate=yes, the daemon
+// first moves itself to a leaf next to the root, and the controllers are
+// enabled on every directory from its former cgroup down to the root.
+func SetupCgroupRoot(root string) ([]string, error) {
+	root = filepath.Clean(root)
+	own, err := ownCgroup()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []string{root}
+	if rel, err := filepath.Rel(own, root); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+		leaf := filepath.Join(own, daemonCgroup)
+		if root == leaf {
+			return nil, fmt.Errorf("cgroup root can't be %s, the daemon moves there", leaf)
+		}
+		if err := moveToCgroup(leaf); err != nil {
+			return nil, err
+		}
+
+		dirs = []string{own}
+		if rel != "." {
+			dir := own
+			for _, name := range strings.Split(rel, "/") {
+				dir = filepath.Join(dir, name)
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup root: %w", err)
+	}
+
+	var missing []string
+	for _, dir := range dirs {
+		if missing, err = enableControllers(dir); err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// ownCgroup returns the directory of the daemon's cgroup
+func ownCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", fmt.Errorf("failed to read own cgroup: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Join(cgroupMount, path), nil
+		}
+	}
+	return "", fmt.Errorf("daemon is not in a cgroup v2 hierarchy")
+}
+
+// moveToCgroup creates a cgroup and moves the daemon into it
+func moveToCgroup(path string) error {
+	if err := os.Mkdir(path, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create daemon cgroup: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+		return fmt.Errorf("failed to move daemon to %s: %w", path, err)
+	}
+	return nil
+}
+
+// enableControllers enables the controllers available in dir for its
+// children and returns the ones that are not available
+func enableControllers(dir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not on a cgroup v2 hierarchy: %w", dir, err)
+	}
+	available := strings.Fields(string(data))
+
+	var missing []string
+	control := filepath.Join(dir, "cgroup.subtree_control")
+	for _, controller := range cgroupControllers {
+		if !slices.Contains(available, controller) {
+			missing = append(missing, controller)
+			continue
+		}
+		if err := os.WriteFile(control, []byte("+"+controller), 0); err != nil {
+			return nil, fmt.Errorf("failed to enable %s controller in %s: %w", controller, dir, err)
+		}
+	}
+	return missing, nil
+}
+
+// newCgroup creates an execution cgroup under root with the given limits
+func newCgroup(root string, limits *models.CgroupLimits) (*cgroup, error) {
+	name := fmt.Sprintf("exec-%d-%d", os.Getpid(), cgroupSeq.Add(1))
+	path := filepath.Join(root, name)
+	if err := os.Mkdir(path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	cg := &cgroup{path: path}
+	if err := cg.setLimits(limits); err != nil {
+		cg.remove()
+		return nil, err
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		cg.remove()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	cg.dir = dir
+
+	return cg, nil
+}
+
+// setLimits writes the configured controls to the cgroup
+func (c *cgroup) setLimits(limits *models.CgroupLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	if limits.MemoryMax != 0 {
+		if err := c.write("memory.max", strconv.FormatUint(uint64(limits.MemoryMax), 10)); err != nil {
+			return err
+		}
+		// Don't let the kernel push the command into swap instead of
+		// enforcing the limit
+		if err := c.write("memory.swap.max", "0"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if limits.CPUMax != "" {
+		if err := c.write("cpu.max", limits.CPUMax); err != nil {
+			return err
+		}
+	}
+	if limits.PidsMax != 0 {
+		if err := c.write("pids.max", strconv.FormatUint(limits.PidsMax, 10)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attach makes cmd start directly inside the cgroup
+func (c *cgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(c.dir.Fd())
+
+	// Kill everything in the cgroup, not just the direct child, when the
+	// context is cancelled or times out
+	cmd.Cancel = c.kill
+}
+
+// kill sends SIGKILL to every process in the cgroup
+func (c *cgroup) kill() error {
+	err := c.write("cgroup.kill", "1")
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// cgroup.kill needs Linux 5.14, fall back to killing each process
+	data, err := os.ReadFile(filepath.Join(c.path, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Fields(string(data)) {
+		if pid, err := strconv.Atoi(line); err == nil {
+			syscall.Kill(pid, syscall.SIGKILL)
+		}
+	}
+	return nil
+}
+
+// stats reads the peak memory usage, consumed CPU time and whether the
+// OOM killer fired for the cgroup
+func (c *cgroup) stats() (peakMemory uint64, cpuTime time.Duration, oomKilled bool) {
+	// memory.peak needs Linux 5.19
+	if data, err := os.ReadFile(filepath.Join(c.path, "memory.peak")); err == nil {
+		peakMemory, _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	}
+
+	if usec, ok := c.readKey("cpu.stat", "usage_usec"); ok {
+		cpuTime = time.Duration(usec) * time.Microsecond
+	}
+
+	if kills, ok := c.readKey("memory.events", "oom_kill"); ok {
+		oomKilled = kills > 0
+	}
+
+	return peakMemory, cpuTime, oomKilled
+}
+
+// remove kills any remaining processes and deletes the cgroup
+func (c *cgroup) remove() {
+	if c.dir != nil {
+		c.dir.Close()
+	}
+
+	// A cgroup can only be removed once all its processes have exited,
+	// which takes a moment after they have been killed
+	for i := 0; i < 50; i++ {
+		err := os.Remove(c.path)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if i == 0 {
+			c.kill()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// write writes value to the named cgroup interface file
+func (c *cgroup) write(file, value string) error {
+	if err := os.WriteFile(filepath.Join(c.path, file), []byte(value), 0); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// readKey reads a "key value" entry from a flat-keyed cgroup file
+func (c *cgroup) readKey(file, key string) (uint64, bool) {
+	f, err := os.Open(filepath.Join(c.path, file))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
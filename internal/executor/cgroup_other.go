@@ -0,0 +1,30 @@
+//go:build !linux
+
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+type cgroup struct{}
+
+// SetupCgroupRoot reports that cgroups are unsupported on this platform
+func SetupCgroupRoot(root string) ([]string, error) {
+	return nil, fmt.Errorf("cgroups are only supported on Linux")
+}
+
+func newCgroup(root string, limits *models.CgroupLimits) (*cgroup, error) {
+	return nil, fmt.Errorf("cgroups are only supported on Linux")
+}
+
+func (c *cgroup) attach(cmd *exec.Cmd) {}
+
+func (c *cgroup) kill() error { return nil }
+
+func (c *cgroup) stats() (uint64, time.Duration, bool) { return 0, 0, false }
+
+func (c *cgroup) remove() {}
@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// ChildCommand is the hidden subcommand that re-executes the sevalet binary
+// to prepare the child process before exec'ing the requested command
+const ChildCommand = "__exec"
+
+const (
+	// childSpecEnv carries the JSON encoded childSpec to the helper
+	childSpecEnv = "SEVALET_CHILD_SPEC"
+
+	// childStatusFD is the helper's end of the status pipe
+	childStatusFD = 3
+
+	// childSetupFailed is the helper's exit code when setup fails
+	childSetupFailed = 127
+)
+
+// childSpec describes what the helper must do before exec
+type childSpec struct {
+	Path     string                 `json:"path"`
+	Args     []string               `json:"args"`
+	Limits   *models.ResourceLimits `json:"limits,omitempty"`
+	Sandbox  *models.Sandbox        `json:"sandbox,omitempty"`
+	Seccomp  *models.Seccomp        `json:"seccomp,omitempty"`
+	Landlock *models.Landlock       `json:"landlock,omitempty"`
+
+	Capabilities *models.Capabilities `json:"capabilities,omitempty"`
+	NoNewPrivs   bool                 `json:"no_new_privs,omitempty"`
+
+	Nice   *int               `json:"nice,omitempty"`
+	IONice *models.IOPriority `json:"ionice,omitempty"`
+}
+
+// needsChild reports whether the options require the child helper
+func (o Options) needsChild() bool {
+	return o.Limits != nil || o.Sandbox != nil || o.Seccomp != nil || o.Landlock != nil ||
+		o.Capabilities != nil || o.NoNewPrivs || o.Nice != nil || o.IONice != nil
+}
+
+// childCommand builds a command that runs the child helper for the given
+// command. The returned file is the read end of the helper's status pipe.
+func childCommand(ctx context.Context, command string, args []string, opts Options) (*exec.Cmd, *os.File, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spec, err := json.Marshal(childSpec{
+		Path:     path,
+		Args:     append([]string{command}, args...),
+		Limits:   opts.Limits,
+		Sandbox:  opts.Sandbox,
+		Seccomp:  opts.Seccomp,
+		Landlock: opts.Landlock,
+
+		Capabilities: opts.Capabilities,
+		NoNewPrivs:   opts.NoNewPrivs,
+
+		Nice:   opts.Nice,
+		IONice: opts.IONice,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode child spec: %w", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create status pipe: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "/proc/self/exe", ChildCommand)
+	cmd.Env = append(os.Environ(), childSpecEnv+"="+string(spec))
+	cmd.ExtraFiles = []*os.File{w}
+	configureSandbox(cmd, opts.Sandbox)
+
+	return cmd, r, nil
+}
+
+// readChildSpec decodes the spec passed by childCommand
+func readChildSpec() (*childSpec, error) {
+	var spec childSpec
+	if err := json.Unmarshal([]byte(os.Getenv(childSpecEnv)), &spec); err != nil {
+		return nil, fmt.Errorf("invalid child spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// childEnviron returns the helper's environment without the spec
+func childEnviron() []string {
+	env := os.Environ()
+	filtered := env[:0]
+	for _, kv := range env {
+		if strings.HasPrefix(kv, childSpecEnv+"=") {
+			continue
+		}
+		filtered = append(filtered, kv)
+	}
+	return filtered
+}
@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/seccomp"
+	"golang.org/x/sys/unix"
+)
+
+// RunChild prepares the current process as described by the spec passed by
+// childCommand and execs the requested command. It does not return; setup
+// errors are written to the status pipe and the helper exits.
+func RunChild() {
+	// Several of the settings applied below are per-thread, so everything
+	// up to exec must happen on the same OS thread
+	runtime.LockOSThread()
+
+	status := os.NewFile(childStatusFD, "status")
+	err := runChild()
+	status.WriteString(err.Error())
+	os.Exit(childSetupFailed)
+}
+
+// runChild only returns if setup or exec fails
+func runChild() error {
+	// Closing the status pipe on exec tells the parent that setup succeeded
+	unix.CloseOnExec(childStatusFD)
+
+	spec, err := readChildSpec()
+	if err != nil {
+		return err
+	}
+
+	if err := setupSandbox(spec.Sandbox); err != nil {
+		return err
+	}
+
+	if err := applyLimits(spec.Limits); err != nil {
+		return err
+	}
+
+	// Raising the priority needs CAP_SYS_NICE, so this must come before
+	// capabilities are dropped
+	if err := applyPriority(spec.Nice, spec.IONice); err != nil {
+		return err
+	}
+
+	if err := applyCapabilities(spec.Capabilities); err != nil {
+		return err
+	}
+
+	// Landlock and seccomp require no_new_privs when unprivileged, and
+	// filters installed by a privileged process shouldn't be escapable
+	// through setuid binaries either
+	if spec.NoNewPrivs || spec.Landlock != nil || spec.Seccomp != nil {
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			return &os.SyscallError{Syscall: "prctl PR_SET_NO_NEW_PRIVS", Err: err}
+		}
+	}
+
+	if err := applyLandlock(spec.Landlock); err != nil {
+		return err
+	}
+
+	// The seccomp filter goes last, so that it doesn't have to permit the
+	// system calls made during setup
+	if err := applySeccomp(spec.Seccomp); err != nil {
+		return err
+	}
+
+	if err := unix.Exec(spec.Path, spec.Args, childEnviron()); err != nil {
+		return &os.PathError{Op: "exec", Path: spec.Path, Err: err}
+	}
+	return nil
+}
+
+// applyLimits sets the configured rlimits on the current process
+func applyLimits(l *models.ResourceLimits) error {
+	if l == nil {
+		return nil
+	}
+
+	set := func(name string, resource int, cur, max uint64) error {
+		if cur == 0 {
+			return nil
+		}
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: cur, Max: max}); err != nil {
+			return &os.SyscallError{Syscall: "setrlimit " + name, Err: err}
+		}
+		return nil
+	}
+
+	// The hard CPU limit is one second above the soft one so that the
+	// kernel sends SIGXCPU before resorting to SIGKILL
+	if err := set("cpu_seconds", unix.RLIMIT_CPU, l.CPUSeconds, l.CPUSeconds+1); err != nil {
+		return err
+	}
+	if err := set("address_space", unix.RLIMIT_AS, uint64(l.AddressSpace), uint64(l.AddressSpace)); err != nil {
+		return err
+	}
+	if err := set("open_files", unix.RLIMIT_NOFILE, l.OpenFiles, l.OpenFiles); err != nil {
+		return err
+	}
+	if err := set("processes", unix.RLIMIT_NPROC, l.Processes, l.Processes); err != nil {
+		return err
+	}
+	return set("file_size", unix.RLIMIT_FSIZE, uint64(l.FileSize), uint64(l.FileSize))
+}
+
+// applyPriority sets the CPU and I/O scheduling priority of the calling
+// thread, which the command inherits on exec
+func applyPriority(nice *int, ionice *models.IOPriority) error {
+	if nice != nil {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, *nice); err != nil {
+			return &os.SyscallError{Syscall: "setpriority", Err: err}
+		}
+	}
+
+	if ionice != nil {
+		const ioprioWhoProcess = 1
+		_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, 0, uintptr(ionice.Value()))
+		if errno != 0 {
+			return &os.SyscallError{Syscall: "ioprio_set", Err: errno}
+		}
+	}
+
+	return nil
+}
+
+// applySeccomp installs the command's system call filter
+func applySeccomp(s *models.Seccomp) error {
+	if s == nil {
+		return nil
+	}
+
+	allow := len(s.Allow) > 0
+	names := s.Deny
+	if allow {
+		// The filter is already active when the command is exec'd
+		names = append(append([]string{}, s.Allow...), "execve")
+	}
+
+	nrs, err := seccomp.Resolve(names)
+	if err != nil {
+		return err
+	}
+
+	return seccomp.Install(seccomp.Filter(nrs, allow))
+}
+
+// limitExceeded returns the name of the limit that killed the process, if any
+func limitExceeded(state *os.ProcessState, l *models.ResourceLimits) string {
+	if l == nil || state == nil {
+		return ""
+	}
+
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return ""
+	}
+
+	switch ws.Signal() {
+	case syscall.SIGXCPU:
+		return "cpu_seconds"
+	case syscall.SIGXFSZ:
+		return "file_size"
+	case syscall.SIGKILL:
+		// Reaching the hard CPU limit sends SIGKILL
+		cpu := state.UserTime() + state.SystemTime()
+		if l.CPUSeconds != 0 && cpu.Seconds() >= float64(l.CPUSeconds) {
+			return "cpu_seconds"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// RunChild reports that child setup is unsupported on this platform
+func RunChild() {
+	status := os.NewFile(childStatusFD, "status")
+	fmt.Fprint(status, "per-command process settings are only supported on Linux")
+	os.Exit(childSetupFailed)
+}
+
+func limitExceeded(state *os.ProcessState, l *models.ResourceLimits) string {
+	return ""
+}
+
+func configureSandbox(cmd *exec.Cmd, s *models.Sandbox) {}
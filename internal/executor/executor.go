@@ -4,49 +4,193 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
-	"strings"
+	"syscall"
 	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
 )
 
+// Options contains per-command settings applied to the child process
+type Options struct {
+	Limits   *models.ResourceLimits
+	Sandbox  *models.Sandbox
+	Seccomp  *models.Seccomp
+	Landlock *models.Landlock
+
+	Capabilities *models.Capabilities
+	NoNewPrivs   bool
+
+	Nice   *int
+	IONice *models.IOPriority
+
+	// TrimOutput strips leading and trailing whitespace from the output
+	TrimOutput bool
+
+	// CombinedOutput records an ordered stream of output lines
+	CombinedOutput bool
+
+	// CgroupRoot enables per-execution cgroups when set
+	CgroupRoot string
+	Cgroup     *models.CgroupLimits
+}
+
 // Result contains the result of command execution
 type Result struct {
 	ExitCode      int
-	Stdout        string
-	Stderr        string
+	Stdout        []byte
+	Stderr        []byte
+	Output        []OutputLine
 	ExecutionTime string
+	LimitExceeded string
+	PeakMemory    uint64
+	CPUTime       time.Duration
+	Signal        syscall.Signal // Signal that terminated the command, if any
 	Error         error
 }
 
 // ExecuteCommand executes the specified command with timeout
-func ExecuteCommand(ctx context.Context, command string, args []string, timeout int) *Result {
+func ExecuteCommand(ctx context.Context, command string, args []string, timeout int, opts Options) *Result {
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// Create command
-	cmd := exec.CommandContext(ctx, command, args...)
+	p, err := newProcess(ctx, command, args, opts)
+	if err != nil {
+		return &Result{
+			Error:    fmt.Errorf("command execution failed: %w", err),
+			ExitCode: -2,
+		}
+	}
+	defer p.close()
 
 	// Create buffers for stdout and stderr
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	p.cmd.Stdout = &stdout
+	p.cmd.Stderr = &stderr
+
+	// Also record the lines of both streams in order if requested
+	var recorder *lineRecorder
+	var stdoutLines, stderrLines *lineWriter
+	if opts.CombinedOutput {
+		recorder = &lineRecorder{}
+		stdoutLines = recorder.writer("stdout")
+		stderrLines = recorder.writer("stderr")
+		p.cmd.Stdout = io.MultiWriter(&stdout, stdoutLines)
+		p.cmd.Stderr = io.MultiWriter(&stderr, stderrLines)
+	}
 
 	// Start time measurement
 	startTime := time.Now()
+	if recorder != nil {
+		recorder.start = startTime
+	}
 
 	// Execute command
-	err := cmd.Run()
+	err = p.start()
+	if err == nil {
+		err = p.wait()
+	}
 
 	// Calculate execution time
 	executionTime := time.Since(startTime).String()
 
 	// Create result
 	result := &Result{
-		Stdout:        strings.TrimSpace(stdout.String()),
-		Stderr:        strings.TrimSpace(stderr.String()),
+		Stdout:        stdout.Bytes(),
+		Stderr:        stderr.Bytes(),
 		ExecutionTime: executionTime,
 	}
+	if opts.TrimOutput {
+		result.Stdout = bytes.TrimSpace(result.Stdout)
+		result.Stderr = bytes.TrimSpace(result.Stderr)
+	}
+	if recorder != nil {
+		stdoutLines.flush()
+		stderrLines.flush()
+		result.Output = recorder.lines
+	}
+
+	p.complete(ctx, err, result)
+	return result
+}
+
+// process is a command prepared for execution
+type process struct {
+	cmd   *exec.Cmd
+	opts  Options
+	setup *os.File // Read end of the child helper's status pipe, if used
+	cg    *cgroup
+}
+
+// newProcess creates a command, going through the child helper when the
+// process needs to be set up before exec, and places it in its own cgroup
+// if configured. The process must be closed when done.
+func newProcess(ctx context.Context, command string, args []string, opts Options) (*process, error) {
+	p := &process{opts: opts}
+
+	if opts.needsChild() {
+		var err error
+		p.cmd, p.setup, err = childCommand(ctx, command, args, opts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.cmd = exec.CommandContext(ctx, command, args...)
+	}
+
+	if opts.CgroupRoot != "" {
+		var err error
+		p.cg, err = newCgroup(opts.CgroupRoot, opts.Cgroup)
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		p.cg.attach(p.cmd)
+	}
+
+	return p, nil
+}
+
+// start starts the process without waiting for it
+func (p *process) start() error {
+	if err := p.cmd.Start(); err != nil {
+		return err
+	}
+
+	// The write end of the status pipe belongs to the child now
+	if p.setup != nil {
+		p.cmd.ExtraFiles[0].Close()
+	}
+	return nil
+}
+
+// wait waits for a started process. If the child helper fails before exec,
+// its status pipe carries an error message; otherwise it is closed without
+// data on exec.
+func (p *process) wait() error {
+	var msg []byte
+	if p.setup != nil {
+		msg, _ = io.ReadAll(p.setup)
+	}
+
+	err := p.cmd.Wait()
+	if len(msg) > 0 {
+		return fmt.Errorf("child setup failed: %s", msg)
+	}
+	return err
+}
+
+// complete fills in the exit status and accounting of result from the error
+// returned by start or wait
+func (p *process) complete(ctx context.Context, err error, result *Result) {
+	// Collect cgroup accounting
+	oomKilled := false
+	if p.cg != nil {
+		result.PeakMemory, result.CPUTime, oomKilled = p.cg.stats()
+	}
 
 	// Handle errors and exit codes
 	if err != nil {
@@ -57,8 +201,19 @@ func ExecuteCommand(ctx context.Context, command string, args []string, timeout
 		} else if exitErr, ok := err.(*exec.ExitError); ok {
 			// Command executed but returned non-zero exit code
 			result.ExitCode = exitErr.ExitCode()
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				result.Signal = status.Signal()
+			}
 			// Don't set error for non-zero exit codes
-			// as this is a normal execution result
+			// as this is a normal execution result,
+			// unless the kernel killed it for exceeding a limit
+			result.LimitExceeded = limitExceeded(exitErr.ProcessState, p.opts.Limits)
+			if oomKilled {
+				result.LimitExceeded = "memory_max"
+			}
+			if result.LimitExceeded != "" {
+				result.Error = fmt.Errorf("command exceeded %s limit", result.LimitExceeded)
+			}
 		} else {
 			// Other execution errors
 			result.Error = fmt.Errorf("command execution failed: %w", err)
@@ -68,6 +223,15 @@ func ExecuteCommand(ctx context.Context, command string, args []string, timeout
 		// Success
 		result.ExitCode = 0
 	}
+}
 
-	return result
+// close releases the status pipe and removes the cgroup
+func (p *process) close() {
+	if p.setup != nil {
+		p.setup.Close()
+		p.cmd.ExtraFiles[0].Close()
+	}
+	if p.cg != nil {
+		p.cg.remove()
+	}
 }
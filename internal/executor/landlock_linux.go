@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// landlockReadAccess is granted beneath read-only paths
+	landlockReadAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+	// landlockFileAccess are the rights that apply to regular files; the
+	// others only make sense on directories
+	landlockFileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_TRUNCATE
+)
+
+// landlockHandledAccess returns the filesystem rights known to the kernel's
+// Landlock ABI version, all of which are restricted by the ruleset
+func landlockHandledAccess(abi int) uint64 {
+	// ABI 1 covers EXECUTE up to MAKE_SYM
+	access := uint64(unix.LANDLOCK_ACCESS_FS_MAKE_SYM<<1 - 1)
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	return access
+}
+
+// applyLandlock restricts the calling thread's filesystem access to the
+// configured paths. The caller must have set no_new_privs.
+func applyLandlock(l *models.Landlock) error {
+	if l == nil {
+		return nil
+	}
+
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return fmt.Errorf("landlock is not supported by the kernel: %w", errno)
+	}
+	handled := landlockHandledAccess(int(abi))
+
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "landlock_create_ruleset", Err: errno}
+	}
+	ruleset := int(fd)
+	defer unix.Close(ruleset)
+
+	for _, path := range l.ReadOnly {
+		if err := addLandlockRule(ruleset, path, landlockReadAccess&handled); err != nil {
+			return err
+		}
+	}
+	for _, path := range l.ReadWrite {
+		if err := addLandlockRule(ruleset, path, handled); err != nil {
+			return err
+		}
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(ruleset), 0, 0); errno != 0 {
+		return &os.SyscallError{Syscall: "landlock_restrict_self", Err: errno}
+	}
+
+	return nil
+}
+
+// addLandlockRule allows access beneath path
+func addLandlockRule(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "landlock", Path: path, Err: err}
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return &os.PathError{Op: "landlock", Path: path, Err: err}
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFileAccess
+	}
+
+	attr := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&attr)), 0, 0, 0)
+	if errno != 0 {
+		return &os.PathError{Op: "landlock_add_rule", Path: path, Err: errno}
+	}
+	return nil
+}
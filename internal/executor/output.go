@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// OutputLine is a line of combined output
+type OutputLine struct {
+	Stream string
+	Offset time.Duration // Monotonic time since the command started
+	Data   []byte
+}
+
+// lineRecorder collects the lines written to several streams in the order
+// in which they arrive
+type lineRecorder struct {
+	mu    sync.Mutex
+	start time.Time
+	lines []OutputLine
+}
+
+// lineWriter splits the output of one stream into lines for its recorder
+type lineWriter struct {
+	recorder *lineRecorder
+	stream   string
+	partial  []byte
+	begun    time.Duration // Offset at which the partial line started
+}
+
+// writer returns an io.Writer recording the lines of the named stream
+func (r *lineRecorder) writer(stream string) *lineWriter {
+	return &lineWriter{recorder: r, stream: stream}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	r := w.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offset := time.Since(r.start)
+	data := p
+	for {
+		if len(data) == 0 {
+			break
+		}
+		if len(w.partial) == 0 {
+			w.begun = offset
+		}
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, data...)
+			break
+		}
+		line := append(w.partial, data[:i]...)
+		w.partial = nil
+		r.lines = append(r.lines, OutputLine{Stream: w.stream, Offset: w.begun, Data: line})
+		data = data[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush records a final line that was not terminated by a newline
+func (w *lineWriter) flush() {
+	r := w.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		r.lines = append(r.lines, OutputLine{Stream: w.stream, Offset: w.begun, Data: w.partial})
+		w.partial = nil
+	}
+}
@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Stage is a command in a pipeline
+type Stage struct {
+	Command string
+	Args    []string
+	Options Options
+}
+
+// PipelineResult contains the result of pipeline execution
+type PipelineResult struct {
+	Stdout        []byte    // Output of the last stage
+	Stages        []*Result // Exit status, stderr and accounting of each stage
+	ExecutionTime string
+	Error         error // Set if the pipeline could not be started
+}
+
+// ExecutePipeline runs the stages concurrently with the stdout of each
+// connected to the stdin of the next, and waits for all of them. The
+// timeout applies to the whole pipeline.
+func ExecutePipeline(ctx context.Context, stages []Stage, timeout int) *PipelineResult {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	// Prepare all stages before starting any of them
+	procs := make([]*process, len(stages))
+	for i, stage := range stages {
+		p, err := newProcess(ctx, stage.Command, stage.Args, stage.Options)
+		if err != nil {
+			return &PipelineResult{
+				Error: fmt.Errorf("stage %d (%s): command execution failed: %w", i+1, stage.Command, err),
+			}
+		}
+		defer p.close()
+		procs[i] = p
+	}
+
+	// Connect the stages
+	var stdout bytes.Buffer
+	stderr := make([]bytes.Buffer, len(procs))
+	var pipes []*os.File
+	defer func() {
+		for _, f := range pipes {
+			f.Close()
+		}
+	}()
+	for i, p := range procs {
+		p.cmd.Stderr = &stderr[i]
+		if i == len(procs)-1 {
+			p.cmd.Stdout = &stdout
+			break
+		}
+
+		r, w, err := os.Pipe()
+		if err != nil {
+			return &PipelineResult{
+				Error: fmt.Errorf("failed to create pipe: %w", err),
+			}
+		}
+		pipes = append(pipes, r, w)
+		p.cmd.Stdout = w
+		procs[i+1].cmd.Stdin = r
+	}
+
+	// Start all stages, stopping the pipeline if one can't be started
+	startTime := time.Now()
+	errs := make([]error, len(procs))
+	started := 0
+	for i, p := range procs {
+		if errs[i] = p.start(); errs[i] != nil {
+			cancel()
+			break
+		}
+		started++
+	}
+
+	// The pipe ends belong to the stages now; closing them here lets each
+	// stage see end of file or a broken pipe when its neighbour exits
+	for _, f := range pipes {
+		f.Close()
+	}
+	pipes = nil
+
+	for i := 0; i < started; i++ {
+		errs[i] = procs[i].wait()
+	}
+
+	result := &PipelineResult{
+		Stdout:        stdout.Bytes(),
+		ExecutionTime: time.Since(startTime).String(),
+	}
+	for i, p := range procs {
+		stage := &Result{Stderr: stderr[i].Bytes()}
+		if p.opts.TrimOutput {
+			stage.Stderr = bytes.TrimSpace(stage.Stderr)
+			if i == len(procs)-1 {
+				result.Stdout = bytes.TrimSpace(result.Stdout)
+			}
+		}
+		if i <= started {
+			p.complete(ctx, errs[i], stage)
+		} else {
+			stage.Error = fmt.Errorf("not started")
+			stage.ExitCode = -2
+		}
+		result.Stages = append(result.Stages, stage)
+	}
+
+	return result
+}
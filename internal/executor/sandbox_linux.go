@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"golang.org/x/sys/unix"
+)
+
+// configureSandbox makes cmd start in new mount, network and IPC
+// namespaces. When the daemon is not running as root, an unprivileged user
+// namespace mapping the daemon's own uid and gid provides the privileges
+// needed to set up the mounts.
+func configureSandbox(cmd *exec.Cmd, s *models.Sandbox) {
+	if s == nil {
+		return
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNS | syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC
+
+	if uid := os.Geteuid(); uid != 0 {
+		gid := os.Getegid()
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER
+		cmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+		cmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+		cmd.SysProcAttr.GidMappingsEnableSetgroups = false
+
+		// Without a uid 0 mapping, exec'ing the helper would drop the
+		// capabilities held in the new user namespace
+		cmd.SysProcAttr.AmbientCaps = []uintptr{unix.CAP_SYS_ADMIN}
+	}
+}
+
+// setupSandbox prepares the mount namespace created by configureSandbox
+func setupSandbox(s *models.Sandbox) error {
+	if s == nil {
+		return nil
+	}
+
+	// Keep the mounts below from propagating back to the host
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return &os.SyscallError{Syscall: "mount /", Err: err}
+	}
+
+	var writable []string
+	for _, path := range s.Tmpfs {
+		if err := unix.Mount("tmpfs", path, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+			return &os.SyscallError{Syscall: "mount tmpfs " + path, Err: err}
+		}
+		writable = append(writable, path)
+	}
+
+	for _, b := range s.Binds {
+		target := b.Target
+		if target == "" {
+			target = b.Source
+		}
+		if err := unix.Mount(b.Source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return &os.SyscallError{Syscall: "mount " + b.Source, Err: err}
+		}
+		if b.Writable {
+			writable = append(writable, target)
+		}
+	}
+
+	// Remount everything read-only, then lift it again for writable mounts
+	ro := &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}
+	if err := unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, ro); err != nil {
+		if err == unix.ENOSYS {
+			return fmt.Errorf("read-only sandbox requires Linux 5.12 or later")
+		}
+		return &os.SyscallError{Syscall: "mount_setattr /", Err: err}
+	}
+	rw := &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY}
+	for _, path := range writable {
+		if err := unix.MountSetattr(unix.AT_FDCWD, path, 0, rw); err != nil {
+			return &os.SyscallError{Syscall: "mount_setattr " + path, Err: err}
+		}
+	}
+
+	// Don't pass the capability raised by configureSandbox on to the command
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return &os.SyscallError{Syscall: "prctl PR_CAP_AMBIENT_CLEAR_ALL", Err: err}
+	}
+
+	return nil
+}
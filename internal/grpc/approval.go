@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/approval"
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// GetApproval returns a request held for approval
+func (s *Server) GetApproval(ctx context.Context, req *pb.GetApprovalRequest) (*pb.ApprovalResponse, error) {
+	record, ok := s.approvals.Get(req.Id)
+	if !ok {
+		return &pb.ApprovalResponse{
+			Success:      false,
+			ErrorMessage: approval.ErrNotFound.Error(),
+		}, nil
+	}
+
+	return &pb.ApprovalResponse{
+		Success:  true,
+		Approval: approvalMessage(record),
+	}, nil
+}
+
+// ListApprovals returns the requests held for approval, most recent first
+func (s *Server) ListApprovals(ctx context.Context, req *pb.ListApprovalsRequest) (*pb.ListApprovalsResponse, error) {
+	resp := &pb.ListApprovalsResponse{}
+	for _, record := range s.approvals.List() {
+		resp.Approvals = append(resp.Approvals, approvalMessage(record))
+	}
+	return resp, nil
+}
+
+// DecideApproval records an approver's decision. The decision that
+// completes the approvals executes the request and waits for its result.
+func (s *Server) DecideApproval(ctx context.Context, req *pb.DecideApprovalRequest) (*pb.ApprovalResponse, error) {
+	record, ready, err := s.approvals.Decide(req.Id, req.Identity, req.Approve, req.Comment)
+
+	// Log the decision (audit log)
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "daemon",
+		Event:     "approval_approved",
+		Command:   record.Command,
+		Args:      record.Args,
+		Approval:  req.Id,
+		Identity:  req.Identity,
+	}
+	if !req.Approve {
+		logEntry.Event = "approval_rejected"
+	}
+	if err != nil {
+		logEntry.Level = "warn"
+		logEntry.Event = "approval_decision_rejected"
+		logEntry.Error = err.Error()
+		s.logJSON(logEntry)
+
+		resp := &pb.ApprovalResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+		if record.ID != "" {
+			resp.Approval = approvalMessage(record)
+		}
+		return resp, nil
+	}
+	s.logJSON(logEntry)
+
+	if ready {
+		// The run belongs to the approval, not to the request that
+		// completed it
+		logEntry := models.LogEntry{Approval: record.ID}
+		if record.Plan != nil {
+			logEntry.Plan = record.Plan.ID
+		}
+		s.approvals.Complete(record.ID, s.execute(context.WithoutCancel(ctx), record.Request, logEntry, record.Plan))
+		record, _ = s.approvals.Get(record.ID)
+	}
+
+	return &pb.ApprovalResponse{
+		Success:  true,
+		Approval: approvalMessage(record),
+	}, nil
+}
+
+// approvalMessage converts an approval record
+func approvalMessage(record approval.Record) *pb.Approval {
+	msg := &pb.Approval{
+		Id:        record.ID,
+		Command:   record.Command,
+		Args:      record.Args,
+		Requester: record.Requester,
+		Status:    record.Status,
+		Required:  int32(record.Required),
+		Created:   record.Created,
+		Expires:   record.Expires,
+		Result:    record.Response,
+	}
+	for _, v := range record.Votes {
+		msg.Votes = append(msg.Votes, &pb.ApprovalVote{
+			Identity: v.Identity,
+			Approve:  v.Approve,
+			Comment:  v.Comment,
+			Time:     v.Time,
+		})
+	}
+	return msg
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/zinrai/sevalet/internal/models"
 	"github.com/zinrai/sevalet/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -36,15 +37,143 @@ func NewClient(socketPath string) (*Client, error) {
 	}, nil
 }
 
-// Execute sends a command execution request to the daemon
-func (c *Client) Execute(ctx context.Context, command string, args []string, timeout int) (*pb.ExecuteResponse, error) {
+// Execute sends a command execution request to the daemon on behalf of an
+// identity
+func (c *Client) Execute(ctx context.Context, request *models.ExecuteRequest, identity string) (*pb.ExecuteResponse, error) {
+	resp, err := c.client.Execute(ctx, executeRequest(request, identity))
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Plan asks the daemon to plan a command execution request on behalf of an
+// identity
+func (c *Client) Plan(ctx context.Context, request *models.ExecuteRequest, identity string) (*pb.PlanResponse, error) {
+	resp, err := c.client.Plan(ctx, executeRequest(request, identity))
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Apply sends a plan token to the daemon on behalf of an identity
+func (c *Client) Apply(ctx context.Context, token, identity string) (*pb.ExecuteResponse, error) {
+	resp, err := c.client.Apply(ctx, &pb.ApplyRequest{Token: token, Identity: identity})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// executeRequest converts a command execution request
+func executeRequest(request *models.ExecuteRequest, identity string) *pb.ExecuteRequest {
 	req := &pb.ExecuteRequest{
-		Command: command,
-		Args:    args,
-		Timeout: int32(timeout),
+		Command:    request.Command,
+		Args:       request.Args,
+		Timeout:    int32(request.Timeout),
+		OutputMode: request.OutputMode,
+		Identity:   identity,
+	}
+	if f := request.Filter; f != nil {
+		req.Filter = &pb.OutputFilter{
+			Head:  int32(f.Head),
+			Tail:  int32(f.Tail),
+			From:  int32(f.From),
+			To:    int32(f.To),
+			Match: f.Match,
+			Regex: f.Regex,
+		}
+	}
+	return req
+}
+
+// ExecutePipeline sends a pipeline execution request to the daemon on
+// behalf of an identity
+func (c *Client) ExecutePipeline(ctx context.Context, request *models.PipelineRequest, identity string) (*pb.PipelineResponse, error) {
+	req := &pb.PipelineRequest{
+		Timeout:  int32(request.Timeout),
+		Identity: identity,
+	}
+	for _, stage := range request.Stages {
+		req.Stages = append(req.Stages, &pb.PipelineStage{
+			Command: stage.Command,
+			Args:    stage.Args,
+		})
 	}
 
-	resp, err := c.client.Execute(ctx, req)
+	resp, err := c.client.ExecutePipeline(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ExecuteRunbook sends a runbook execution request to the daemon on behalf
+// of an identity
+func (c *Client) ExecuteRunbook(ctx context.Context, name, identity string) (*pb.RunbookResponse, error) {
+	resp, err := c.client.ExecuteRunbook(ctx, &pb.RunbookRequest{Name: name, Identity: identity})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListSchedules asks the daemon for the state of its schedules
+func (c *Client) ListSchedules(ctx context.Context) (*pb.ListSchedulesResponse, error) {
+	resp, err := c.client.ListSchedules(ctx, &pb.ListSchedulesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// WaitScheduleCompletions asks the daemon for the completed scheduled runs
+// whose webhooks are to be sent, which it answers once there are any or
+// after a while without
+func (c *Client) WaitScheduleCompletions(ctx context.Context) ([]*pb.ScheduleCompletion, error) {
+	resp, err := c.client.WaitScheduleCompletions(ctx, &pb.WaitScheduleCompletionsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp.Completions, nil
+}
+
+// GetApproval asks the daemon for a request held for approval
+func (c *Client) GetApproval(ctx context.Context, id string) (*pb.ApprovalResponse, error) {
+	resp, err := c.client.GetApproval(ctx, &pb.GetApprovalRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListApprovals asks the daemon for the requests held for approval
+func (c *Client) ListApprovals(ctx context.Context) (*pb.ListApprovalsResponse, error) {
+	resp, err := c.client.ListApprovals(ctx, &pb.ListApprovalsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("gRPC call failed: %w", err)
+	}
+
+	return resp, nil
+}
+
+// DecideApproval sends an approver's decision to the daemon
+func (c *Client) DecideApproval(ctx context.Context, id, identity string, decision *models.ApprovalDecision) (*pb.ApprovalResponse, error) {
+	resp, err := c.client.DecideApproval(ctx, &pb.DecideApprovalRequest{
+		Id:       id,
+		Identity: identity,
+		Approve:  decision.Decision == models.DecisionApprove,
+		Comment:  decision.Comment,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("gRPC call failed: %w", err)
 	}
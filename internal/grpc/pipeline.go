@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/executor"
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/validator"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// ExecutePipeline handles pipeline execution requests. Every stage is
+// validated like a single command before any of them runs.
+func (s *Server) ExecutePipeline(ctx context.Context, req *pb.PipelineRequest) (*pb.PipelineResponse, error) {
+	// Log the request (audit log)
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "daemon",
+		Event:     "pipeline_request",
+		Identity:  req.Identity,
+	}
+	for _, stage := range req.Stages {
+		logEntry.Stages = append(logEntry.Stages, models.PipelineStage{Command: stage.Command, Args: stage.Args})
+	}
+
+	reject := func(err error) (*pb.PipelineResponse, error) {
+		logEntry.Event = "pipeline_rejected"
+		logEntry.Error = err.Error()
+		s.logJSON(logEntry)
+
+		// Return error response (not gRPC error)
+		resp := &pb.PipelineResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+		resp.ErrorCode, resp.NextAllowed = timeError(err)
+		return resp, nil
+	}
+
+	if len(req.Stages) == 0 || len(req.Stages) > models.MaxPipelineStages {
+		return reject(fmt.Errorf("pipeline must have between 1 and %d stages", models.MaxPipelineStages))
+	}
+
+	// Validate every stage
+	commands := make([]*models.Command, len(req.Stages))
+	stages := make([]executor.Stage, len(req.Stages))
+	for i, stage := range req.Stages {
+		if err := validator.ValidateCommand(stage.Command, stage.Args, &s.config.Commands); err != nil {
+			return reject(err)
+		}
+
+		commands[i] = s.config.Commands.FindCommand(stage.Command)
+		if commands[i].RequiresApproval != nil {
+			return reject(models.ErrApprovalRequired)
+		}
+		if err := s.calendar.ValidateTime(commands[i], time.Now()); err != nil {
+			return reject(err)
+		}
+		stages[i] = executor.Stage{
+			Command: stage.Command,
+			Args:    stage.Args,
+			Options: s.executorOptions(commands[i]),
+		}
+	}
+
+	// Check timeout limits
+	timeout := int(req.Timeout)
+	if timeout <= 0 {
+		timeout = s.config.DefaultTimeout
+	}
+	if timeout > s.config.MaxExecutionTime {
+		timeout = s.config.MaxExecutionTime
+	}
+
+	// Output passes through every stage, so all of their redaction rules
+	// apply to all of it
+	redactor, err := s.redactor(commands...)
+	if err != nil {
+		logEntry.Level = "error"
+		return reject(err)
+	}
+	for i := range logEntry.Stages {
+		logEntry.Stages[i].Args = redactArgs(redactor, logEntry.Stages[i].Args)
+	}
+
+	// Execute pipeline
+	result := executor.ExecutePipeline(ctx, stages, timeout)
+	if result.Error != nil {
+		logEntry.Level = "error"
+		return reject(result.Error)
+	}
+
+	// Redact secrets from the output
+	var n int
+	result.Stdout, n = redactor.Redact(result.Stdout)
+	logEntry.Redactions += n
+	for _, stage := range result.Stages {
+		stage.Stderr, n = redactor.Redact(stage.Stderr)
+		logEntry.Redactions += n
+	}
+
+	// Build response
+	resp := &pb.PipelineResponse{
+		Success:       true,
+		Succeeded:     true,
+		Stdout:        result.Stdout,
+		ExecutionTime: result.ExecutionTime,
+	}
+
+	last := len(result.Stages) - 1
+	for i, stage := range result.Stages {
+		stageResp := &pb.StageResult{
+			Command:         stages[i].Command,
+			ExitCode:        int32(stage.ExitCode),
+			Stderr:          stage.Stderr,
+			LimitExceeded:   stage.LimitExceeded,
+			PeakMemoryBytes: stage.PeakMemory,
+		}
+		if stage.Signal != 0 {
+			stageResp.Signal = stage.Signal.String()
+		}
+		if stage.CPUTime > 0 {
+			stageResp.CpuTime = stage.CPUTime.String()
+		}
+
+		// Stages before the last may be stopped by a broken pipe when a
+		// later stage exits early, as in a shell. Output matches only
+		// apply to the last stage, whose stdout is captured.
+		if stage.Error != nil {
+			stageResp.ErrorMessage = stage.Error.Error()
+			resp.Success = false
+		} else if i == last {
+			stageResp.Succeeded = commands[i].Succeeded(stage.ExitCode, result.Stdout, stage.Stderr)
+		} else {
+			stageResp.Succeeded = stage.Signal == syscall.SIGPIPE || commands[i].SuccessExitCode(stage.ExitCode)
+		}
+		resp.Succeeded = resp.Succeeded && stageResp.Succeeded
+
+		resp.Stages = append(resp.Stages, stageResp)
+		logEntry.ExitCodes = append(logEntry.ExitCodes, stage.ExitCode)
+	}
+
+	if !resp.Success {
+		resp.ErrorMessage = "pipeline stage failed"
+		logEntry.Error = resp.ErrorMessage
+	}
+
+	// Log execution result
+	logEntry.Event = "pipeline_executed"
+	logEntry.ExecutionTime = result.ExecutionTime
+	s.logJSON(logEntry)
+
+	return resp, nil
+}
@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// Plan validates a request without running it and returns what it would
+// run, with a token that runs exactly that when applied
+func (s *Server) Plan(ctx context.Context, req *pb.ExecuteRequest) (*pb.PlanResponse, error) {
+	// Log the request (audit log)
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "daemon",
+		Event:     "plan_request",
+		Command:   req.Command,
+		Args:      req.Args,
+		Identity:  req.Identity,
+	}
+
+	reject := func(err error) (*pb.PlanResponse, error) {
+		logEntry.Event = "plan_rejected"
+		logEntry.Error = err.Error()
+		s.logJSON(logEntry)
+
+		// Return error response (not gRPC error)
+		resp := &pb.PlanResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+		resp.ErrorCode, resp.NextAllowed = timeError(err)
+		return resp, nil
+	}
+
+	p, err := s.prepare(req)
+	if err != nil {
+		return reject(err)
+	}
+	redactor, err := s.redactor(p.command)
+	if err != nil {
+		logEntry.Level = "error"
+		return reject(err)
+	}
+
+	// Keep secrets passed as arguments out of the audit log
+	logEntry.Args = redactArgs(redactor, req.Args)
+
+	opts := s.executorOptions(p.command)
+	planned := &models.Plan{
+		Command:    req.Command,
+		Args:       req.Args,
+		Timeout:    p.timeout,
+		OutputMode: p.outputMode,
+		Filter:     p.filter,
+		Limits:     opts.Limits,
+		Cgroup:     opts.Cgroup,
+		Nice:       opts.Nice,
+		IONice:     opts.IONice,
+		Requester:  req.Identity,
+	}
+	token, err := s.plans.Sign(planned)
+	if err != nil {
+		logEntry.Level = "error"
+		return reject(err)
+	}
+	data, err := json.Marshal(planned)
+	if err != nil {
+		logEntry.Level = "error"
+		return reject(err)
+	}
+
+	logEntry.Event = "plan_created"
+	logEntry.Plan = planned.ID
+	s.logJSON(logEntry)
+
+	return &pb.PlanResponse{
+		Success: true,
+		Token:   token,
+		Plan:    data,
+	}, nil
+}
+
+// Apply runs the request a plan token describes. The daemon signed the
+// token with a key of its own, so the command, arguments and settings are
+// the ones that were planned. The request is validated again, and the plan
+// is only used up when it passes. A request held for approval keeps the
+// plan, and runs with the planned settings when approved.
+func (s *Server) Apply(ctx context.Context, req *pb.ApplyRequest) (*pb.ExecuteResponse, error) {
+	planned, err := s.plans.Verify(req.Token, req.Identity)
+	if err != nil {
+		s.logJSON(models.LogEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Level:     "warn",
+			Mode:      "daemon",
+			Event:     "plan_rejected",
+			Identity:  req.Identity,
+			Error:     err.Error(),
+		})
+
+		return &pb.ExecuteResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	request := &pb.ExecuteRequest{
+		Command:    planned.Command,
+		Args:       planned.Args,
+		Timeout:    int32(planned.Timeout),
+		OutputMode: planned.OutputMode,
+		Identity:   req.Identity,
+	}
+	if f := planned.Filter; f != nil {
+		request.Filter = &pb.OutputFilter{
+			Head:  int32(f.Head),
+			Tail:  int32(f.Tail),
+			From:  int32(f.From),
+			To:    int32(f.To),
+			Match: f.Match,
+			Regex: f.Regex,
+		}
+	}
+
+	return s.execute(ctx, request, models.LogEntry{Plan: planned.ID}, planned), nil
+}
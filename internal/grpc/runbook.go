@@ -0,0 +1,219 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/executor"
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// ExecuteRunbook handles runbook execution requests. The runbook's
+// commands were validated when the configuration was loaded.
+func (s *Server) ExecuteRunbook(ctx context.Context, req *pb.RunbookRequest) (*pb.RunbookResponse, error) {
+	// Log the request (audit log)
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "daemon",
+		Event:     "runbook_request",
+		Runbook:   req.Name,
+		Identity:  req.Identity,
+	}
+
+	runbook := models.FindRunbook(s.config.Runbooks, req.Name)
+	if runbook == nil {
+		logEntry.Event = "runbook_rejected"
+		logEntry.Error = "runbook not found"
+		s.logJSON(logEntry)
+
+		// Return error response (not gRPC error)
+		return &pb.RunbookResponse{
+			Success:      false,
+			ErrorMessage: logEntry.Error,
+		}, nil
+	}
+
+	return s.runRunbook(ctx, runbook, logEntry), nil
+}
+
+// runRunbook runs a runbook and logs its steps and result with the fields
+// of logEntry
+func (s *Server) runRunbook(ctx context.Context, runbook *models.Runbook, logEntry models.LogEntry) *pb.RunbookResponse {
+	// Don't start a runbook that can't finish for its steps' maintenance
+	// windows or change freezes
+	for _, step := range runbook.Steps {
+		if err := s.calendar.ValidateTime(s.config.Commands.FindCommand(step.Command), time.Now()); err != nil {
+			logEntry.Event = "runbook_rejected"
+			logEntry.Step = step.Name
+			logEntry.Error = err.Error()
+			s.logJSON(logEntry)
+
+			resp := &pb.RunbookResponse{
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}
+			resp.ErrorCode, resp.NextAllowed = timeError(err)
+			return resp
+		}
+	}
+
+	stepLog := logEntry
+	stepLog.Event = "runbook_step_executed"
+
+	timeout := runbook.Timeout
+	if timeout <= 0 {
+		timeout = s.config.MaxExecutionTime
+	}
+	runbookCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	startTime := time.Now()
+	resp := &pb.RunbookResponse{
+		Success:   true,
+		Succeeded: true,
+	}
+
+	exitCodes := make(map[string]int)
+	previous := ""
+	for _, step := range runbook.Steps {
+		if step.When != nil {
+			ref := step.When.Step
+			if ref == "" {
+				ref = previous
+			}
+			code, ok := exitCodes[ref]
+			if !ok || !slices.Contains(step.When.ExitCodes, code) {
+				resp.Steps = append(resp.Steps, &pb.StepResult{
+					Name:    step.Name,
+					Command: step.Command,
+					Args:    step.Args,
+					Status:  models.StepSkipped,
+				})
+				previous = step.Name
+				continue
+			}
+		}
+
+		stepLog.Step = step.Name
+		result, stepResult := s.runStep(runbookCtx, stepLog, step, "")
+		resp.Steps = append(resp.Steps, stepResult)
+		if result != nil && result.Error == nil {
+			exitCodes[step.Name] = result.ExitCode
+		}
+		previous = step.Name
+
+		if stepResult.Status != models.StepFailed {
+			continue
+		}
+
+		// Compensation runs even if the runbook has timed out or the
+		// request was cancelled, each step bound by its own timeout
+		for _, compensation := range step.OnFailure {
+			stepLog.Step = compensation.Name
+			_, compResult := s.runStep(context.WithoutCancel(ctx), stepLog, compensation, step.Name)
+			resp.Steps = append(resp.Steps, compResult)
+		}
+
+		if !step.ContinueOnFailure {
+			resp.Succeeded = false
+			break
+		}
+	}
+
+	resp.ExecutionTime = time.Since(startTime).String()
+	if !resp.Succeeded {
+		resp.ErrorMessage = "runbook step failed"
+	}
+
+	// Log execution result
+	logEntry.Event = "runbook_executed"
+	logEntry.ExecutionTime = resp.ExecutionTime
+	logEntry.Error = resp.ErrorMessage
+	s.logJSON(logEntry)
+
+	return resp
+}
+
+// runStep runs a runbook step like a single command and logs it with the
+// fields of logEntry. The result is nil if the step could not be prepared.
+// Steps can't use commands that require approval, which the configuration
+// rejects. Maintenance windows and change freezes still apply, except to
+// compensation steps, which undo a failed step.
+func (s *Server) runStep(ctx context.Context, logEntry models.LogEntry, step models.RunbookStep, compensates string) (*executor.Result, *pb.StepResult) {
+	logEntry.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	logEntry.Command = step.Command
+	logEntry.Args = step.Args
+	stepResult := &pb.StepResult{
+		Name:        step.Name,
+		Command:     step.Command,
+		Args:        step.Args,
+		Status:      models.StepFailed,
+		Compensates: compensates,
+	}
+
+	command := s.config.Commands.FindCommand(step.Command)
+	if compensates == "" {
+		if err := s.calendar.ValidateTime(command, time.Now()); err != nil {
+			logEntry.Error = err.Error()
+			s.logJSON(logEntry)
+
+			stepResult.ErrorMessage = err.Error()
+			return nil, stepResult
+		}
+	}
+
+	redactor, err := s.redactor(command)
+	if err != nil {
+		logEntry.Level = "error"
+		logEntry.Error = err.Error()
+		s.logJSON(logEntry)
+
+		stepResult.ErrorMessage = err.Error()
+		return nil, stepResult
+	}
+	logEntry.Args = redactArgs(redactor, step.Args)
+	stepResult.Args = logEntry.Args
+
+	timeout := step.Timeout
+	if timeout <= 0 {
+		timeout = s.config.DefaultTimeout
+	}
+	if timeout > s.config.MaxExecutionTime {
+		timeout = s.config.MaxExecutionTime
+	}
+
+	result := executor.ExecuteCommand(ctx, step.Command, step.Args, timeout, s.executorOptions(command))
+
+	// Redact secrets from the output
+	var n int
+	result.Stdout, n = redactor.Redact(result.Stdout)
+	logEntry.Redactions += n
+	result.Stderr, n = redactor.Redact(result.Stderr)
+	logEntry.Redactions += n
+
+	if result.Error == nil && command.Succeeded(result.ExitCode, result.Stdout, result.Stderr) {
+		stepResult.Status = models.StepSucceeded
+	}
+	stepResult.ExitCode = int32(result.ExitCode)
+	stepResult.Stdout = result.Stdout
+	stepResult.Stderr = result.Stderr
+	stepResult.ExecutionTime = result.ExecutionTime
+
+	// Log execution result
+	logEntry.ExitCode = result.ExitCode
+	logEntry.ExecutionTime = result.ExecutionTime
+	logEntry.LimitExceeded = result.LimitExceeded
+	if result.Error != nil {
+		stepResult.ErrorMessage = result.Error.Error()
+		logEntry.Error = stepResult.ErrorMessage
+	} else if stepResult.Status == models.StepFailed {
+		logEntry.Error = fmt.Sprintf("step failed with exit code %d", result.ExitCode)
+	}
+	s.logJSON(logEntry)
+
+	return result, stepResult
+}
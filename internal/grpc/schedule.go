@@ -0,0 +1,217 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/pb"
+)
+
+// StartSchedules starts running the configured schedules
+func (s *Server) StartSchedules() {
+	s.scheduler.Start()
+}
+
+// Close stops the schedules, waiting for runs in progress, and releases
+// the API waiting for their completions
+func (s *Server) Close() {
+	s.scheduler.Stop()
+	s.completions.close()
+}
+
+// ListSchedules reports the state of the configured schedules
+func (s *Server) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	resp := &pb.ListSchedulesResponse{}
+	for _, status := range s.scheduler.Status() {
+		resp.Schedules = append(resp.Schedules, &pb.ScheduleStatus{
+			Name:         status.Name,
+			Cron:         status.Cron,
+			Command:      status.Command,
+			Args:         status.Args,
+			Runbook:      status.Runbook,
+			Timeout:      int32(status.Timeout),
+			Jitter:       int32(status.Jitter),
+			Overlap:      status.Overlap,
+			Running:      status.Running,
+			Queued:       status.Queued,
+			LastRun:      status.LastRun,
+			LastStatus:   status.LastStatus,
+			LastDuration: status.LastDuration,
+			LastError:    status.LastError,
+			NextRun:      status.NextRun,
+			Runs:         status.Runs,
+			Skipped:      status.Skipped,
+			CallbackUrl:  status.CallbackURL,
+		})
+	}
+
+	return resp, nil
+}
+
+// WaitScheduleCompletions hands the completed runs of schedules with a
+// callback URL to the API, which sends their webhooks. It waits up to
+// completionWait for one if there are none.
+func (s *Server) WaitScheduleCompletions(ctx context.Context, req *pb.WaitScheduleCompletionsRequest) (*pb.WaitScheduleCompletionsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, completionWait)
+	defer cancel()
+
+	return &pb.WaitScheduleCompletionsResponse{Completions: s.completions.take(ctx)}, nil
+}
+
+// runSchedule runs a schedule and queues its completion for the API to
+// send to its callback URL
+func (s *Server) runSchedule(ctx context.Context, schedule *models.Schedule) error {
+	start := time.Now()
+	err := s.runScheduled(ctx, schedule)
+
+	if schedule.CallbackURL != "" {
+		completion := &pb.ScheduleCompletion{
+			Schedule:    schedule.Name,
+			CallbackUrl: schedule.CallbackURL,
+			Started:     start.UTC().Format(time.RFC3339),
+			Duration:    time.Since(start).String(),
+			Status:      models.StepSucceeded,
+		}
+		if err != nil {
+			completion.Status = models.StepFailed
+			completion.Error = err.Error()
+		}
+		if dropped := s.completions.push(completion); dropped != nil {
+			s.logJSON(models.LogEntry{
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Level:     "warn",
+				Mode:      "daemon",
+				Event:     "schedule_completion_dropped",
+				Schedule:  dropped.Schedule,
+				URL:       dropped.CallbackUrl,
+				Error:     "too many completions waiting for the API",
+			})
+		}
+	}
+
+	return err
+}
+
+// runScheduled runs a schedule's command like a runbook step, or its
+// runbook, logging the results with the schedule's name
+func (s *Server) runScheduled(ctx context.Context, schedule *models.Schedule) error {
+	logEntry := models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "info",
+		Mode:      "daemon",
+		Schedule:  schedule.Name,
+	}
+
+	if schedule.Runbook != "" {
+		logEntry.Event = "runbook_request"
+		logEntry.Runbook = schedule.Runbook
+
+		resp := s.runRunbook(ctx, models.FindRunbook(s.config.Runbooks, schedule.Runbook), logEntry)
+		if !resp.Succeeded {
+			return errors.New(resp.ErrorMessage)
+		}
+		return nil
+	}
+
+	logEntry.Event = "command_executed"
+	step := models.RunbookStep{
+		Name:    schedule.Name,
+		Command: schedule.Command,
+		Args:    schedule.Args,
+		Timeout: schedule.Timeout,
+	}
+	result, stepResult := s.runStep(ctx, logEntry, step, "")
+	if stepResult.Status == models.StepSucceeded {
+		return nil
+	}
+	if stepResult.ErrorMessage != "" {
+		return errors.New(stepResult.ErrorMessage)
+	}
+	return fmt.Errorf("command failed with exit code %d", result.ExitCode)
+}
+
+// skipSchedule logs a run dropped because the previous one was running
+func (s *Server) skipSchedule(schedule *models.Schedule) {
+	s.logJSON(models.LogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Level:     "warn",
+		Mode:      "daemon",
+		Event:     "schedule_skipped",
+		Schedule:  schedule.Name,
+		Error:     "previous run is still in progress",
+	})
+}
+
+// Completions wait for the API in a bounded queue, and the API's requests
+// for them are answered after completionWait at the latest
+const (
+	maxCompletions = 1000
+	completionWait = 30 * time.Second
+)
+
+// completionQueue holds completed scheduled runs until the API takes them
+type completionQueue struct {
+	mu     sync.Mutex
+	items  []*pb.ScheduleCompletion
+	ready  chan struct{} // Signalled when items are added
+	closed chan struct{}
+	once   sync.Once
+}
+
+// newCompletionQueue creates an empty queue
+func newCompletionQueue() *completionQueue {
+	return &completionQueue{
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+// push adds a completion, returning the oldest one if it had to be dropped
+// to make room
+func (q *completionQueue) push(c *pb.ScheduleCompletion) *pb.ScheduleCompletion {
+	q.mu.Lock()
+	var dropped *pb.ScheduleCompletion
+	if len(q.items) == maxCompletions {
+		dropped = q.items[0]
+		q.items = q.items[1:]
+	}
+	q.items = append(q.items, c)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// take removes and returns the queued completions, waiting for one until
+// ctx is done or the queue is closed
+func (q *completionQueue) take(ctx context.Context) []*pb.ScheduleCompletion {
+	for {
+		q.mu.Lock()
+		items := q.items
+		q.items = nil
+		q.mu.Unlock()
+		if len(items) > 0 {
+			return items
+		}
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return nil
+		case <-q.closed:
+			return nil
+		}
+	}
+}
+
+// close releases the callers waiting in take
+func (q *completionQueue) close() {
+	q.once.Do(func() { close(q.closed) })
+}
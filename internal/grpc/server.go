@@ -3,12 +3,21 @@ package grpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/zinrai/sevalet/internal/approval"
+	"github.com/zinrai/sevalet/internal/cache"
 	"github.com/zinrai/sevalet/internal/config"
 	"github.com/zinrai/sevalet/internal/executor"
 	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/output"
+	"github.com/zinrai/sevalet/internal/plan"
+	"github.com/zinrai/sevalet/internal/redact"
+	"github.com/zinrai/sevalet/internal/schedule"
 	"github.com/zinrai/sevalet/internal/validator"
 	"github.com/zinrai/sevalet/pb"
 	"google.golang.org/grpc/codes"
@@ -18,58 +27,286 @@ import (
 // Server implements the CommandExecutor service
 type Server struct {
 	pb.UnimplementedCommandExecutorServer
-	config *config.DaemonConfig
+	config      *config.DaemonConfig
+	scheduler   *schedule.Scheduler
+	completions *completionQueue
+	results     *cache.Cache[cachedResult]
+	approvals   *approval.Store
+	plans       *plan.Signer
+	calendar    *validator.Calendar
 }
 
-// NewServer creates a new gRPC server instance
-func NewServer(config *config.DaemonConfig) pb.CommandExecutorServer {
-	return &Server{
-		config: config,
+// cachedResult is the redacted result of a read-only command
+type cachedResult struct {
+	result     executor.Result
+	redactions int
+}
+
+// NewServer creates a new gRPC server instance. Its schedules are started
+// separately with StartSchedules.
+func NewServer(config *config.DaemonConfig) (*Server, error) {
+	s := &Server{
+		config:      config,
+		completions: newCompletionQueue(),
+		results:     cache.New[cachedResult](),
+		approvals:   approval.New(),
+		plans:       plan.New(time.Duration(config.PlanTTL) * time.Second),
+	}
+
+	calendar, err := validator.NewCalendar(config.Windows, config.Freezes)
+	if err != nil {
+		return nil, err
 	}
+	s.calendar = calendar
+
+	scheduler, err := schedule.New(config.Schedules, s.runSchedule, s.skipSchedule)
+	if err != nil {
+		return nil, err
+	}
+	s.scheduler = scheduler
+
+	return s, nil
 }
 
 // Execute handles command execution requests
 func (s *Server) Execute(ctx context.Context, req *pb.ExecuteRequest) (*pb.ExecuteResponse, error) {
-	// Log the request (audit log)
-	logEntry := models.LogEntry{
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Level:     "info",
-		Mode:      "daemon",
-		Event:     "command_request",
-		Command:   req.Command,
-		Args:      req.Args,
-	}
+	return s.execute(ctx, req, models.LogEntry{}, nil), nil
+}
+
+// prepared is a validated request with the settings it runs with
+type prepared struct {
+	command    *models.Command
+	timeout    int
+	outputMode string
+	filter     *models.OutputFilter
+}
 
+// prepare validates a request and works out the settings it runs with
+func (s *Server) prepare(req *pb.ExecuteRequest) (*prepared, error) {
 	// Validate command
-	err := validator.ValidateCommand(req.Command, req.Args, &s.config.Commands)
+	if err := validator.ValidateCommand(req.Command, req.Args, &s.config.Commands); err != nil {
+		return nil, err
+	}
+
+	// Check the command's maintenance window and change freezes
+	command := s.config.Commands.FindCommand(req.Command)
+	if err := s.calendar.ValidateTime(command, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Check output mode
+	if err := models.ValidateOutputMode(req.OutputMode); err != nil {
+		return nil, err
+	}
+
+	// Check timeout limits
+	timeout := int(req.Timeout)
+	if timeout <= 0 {
+		timeout = s.config.DefaultTimeout
+	}
+	if timeout > s.config.MaxExecutionTime {
+		timeout = s.config.MaxExecutionTime
+	}
+
+	// Check the output filter against the command's policy
+	filter := outputFilter(req.Filter)
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := command.Filter.Check(filter); err != nil {
+		return nil, err
+	}
+
+	outputMode := req.OutputMode
+	if outputMode == "" {
+		outputMode = command.OutputMode
+	}
+
+	return &prepared{
+		command:    command,
+		timeout:    timeout,
+		outputMode: outputMode,
+		filter:     filter,
+	}, nil
+}
+
+// execute validates and runs a request, logging it with the fields of
+// logEntry. Requests for commands that require approval are held instead,
+// unless logEntry names the approval that released them. A request from a
+// plan consumes the plan once it passed validation and runs with the
+// planned limits and priorities.
+func (s *Server) execute(ctx context.Context, req *pb.ExecuteRequest, logEntry models.LogEntry, planned *models.Plan) *pb.ExecuteResponse {
+	// Log the request (audit log)
+	logEntry.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	logEntry.Level = "info"
+	logEntry.Mode = "daemon"
+	logEntry.Event = "command_request"
+	logEntry.Command = req.Command
+	logEntry.Args = req.Args
+	logEntry.Identity = req.Identity
+
+	p, err := s.prepare(req)
 	if err != nil {
 		logEntry.Event = "command_rejected"
 		logEntry.Error = err.Error()
 		s.logJSON(logEntry)
 
 		// Return error response (not gRPC error)
+		resp := &pb.ExecuteResponse{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+		resp.ErrorCode, resp.NextAllowed = timeError(err)
+		return resp
+	}
+	command, timeout, filter := p.command, p.timeout, p.filter
+
+	// Prepare redaction before running anything, so that output can't leave
+	// the daemon unredacted
+	redactor, err := s.redactor(command)
+	if err != nil {
+		logEntry.Level = "error"
+		logEntry.Event = "command_rejected"
+		logEntry.Error = err.Error()
+		s.logJSON(logEntry)
+
 		return &pb.ExecuteResponse{
 			Success:      false,
 			ErrorMessage: err.Error(),
-		}, nil
+		}
 	}
 
-	// Check timeout limits
-	timeout := int(req.Timeout)
-	if timeout <= 0 {
-		timeout = s.config.DefaultTimeout
+	// Keep secrets passed as arguments out of the audit log
+	logEntry.Args = redactArgs(redactor, req.Args)
+
+	hold := command.RequiresApproval != nil && logEntry.Approval == ""
+	if hold && req.Identity == "" {
+		logEntry.Event = "command_rejected"
+		logEntry.Error = approval.ErrNoIdentity.Error()
+		s.logJSON(logEntry)
+
+		return &pb.ExecuteResponse{
+			Success:      false,
+			ErrorMessage: approval.ErrNoIdentity.Error(),
+		}
 	}
-	if timeout > s.config.MaxExecutionTime {
-		timeout = s.config.MaxExecutionTime
+
+	// The plan is used up only by a request that is run or held, so a
+	// rejected one can be applied again, such as after a change freeze. A
+	// held request used it up when it was held.
+	consumePlan := func() *pb.ExecuteResponse {
+		if planned == nil || logEntry.Approval != "" {
+			return nil
+		}
+		if err := s.plans.Consume(planned); err != nil {
+			logEntry.Level = "warn"
+			logEntry.Event = "plan_rejected"
+			logEntry.Error = err.Error()
+			s.logJSON(logEntry)
+
+			return &pb.ExecuteResponse{
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		return nil
+	}
+
+	// Hold the request until other identities approve it
+	if hold {
+		record, err := s.approvals.Create(req, planned, logEntry.Args, req.Identity, *command.RequiresApproval)
+		if err != nil {
+			logEntry.Level = "warn"
+			logEntry.Event = "command_rejected"
+			logEntry.Error = err.Error()
+			s.logJSON(logEntry)
+
+			return &pb.ExecuteResponse{
+				Success:      false,
+				ErrorMessage: err.Error(),
+			}
+		}
+		if resp := consumePlan(); resp != nil {
+			s.approvals.Remove(record.ID)
+			return resp
+		}
+
+		logEntry.Event = "approval_requested"
+		logEntry.Approval = record.ID
+		s.logJSON(logEntry)
+
+		return &pb.ExecuteResponse{
+			Success:  true,
+			Approval: approvalMessage(record),
+		}
+	}
+
+	if resp := consumePlan(); resp != nil {
+		return resp
 	}
 
 	// Execute command
-	result := executor.ExecuteCommand(ctx, req.Command, req.Args, timeout)
+	opts := s.executorOptions(command)
+	if planned != nil {
+		opts.Limits = planned.Limits
+		opts.Cgroup = planned.Cgroup
+		opts.Nice = planned.Nice
+		opts.IONice = planned.IONice
+	}
+	outputMode := p.outputMode
+	opts.CombinedOutput = outputMode == models.OutputModeCombined
+	runCtx := ctx
+	run := func() (cachedResult, bool) {
+		result := executor.ExecuteCommand(runCtx, req.Command, req.Args, timeout, opts)
+
+		// Redact secrets from the output
+		var n, redactions int
+		result.Stdout, n = redactor.Redact(result.Stdout)
+		redactions += n
+		result.Stderr, n = redactor.Redact(result.Stderr)
+		redactions += n
+		redactOutputLines(redactor, result.Output)
+
+		// Only completed runs are worth sharing
+		return cachedResult{result: *result, redactions: redactions}, result.Error == nil
+	}
+
+	// Identical requests for read-only commands share results. A shared run
+	// is bound by its timeout only, not by the request that started it.
+	var entry cachedResult
+	var cached bool
+	var cacheAge time.Duration
+	if command.ReadOnly && command.CacheTTL > 0 {
+		runCtx = context.WithoutCancel(ctx)
+		key := cacheKey(req.Command, req.Args, outputMode, timeout, opts)
+		entry, cacheAge, cached = s.results.Do(key, time.Duration(command.CacheTTL)*time.Second, run)
+	} else {
+		entry, _ = run()
+	}
+	result := &entry.result
+	if !cached {
+		logEntry.Redactions = entry.redactions
+	}
+	logEntry.Cached = cached
+
+	// Success criteria see the whole output, filters only change what is
+	// returned
+	succeeded := result.Error == nil && command.Succeeded(result.ExitCode, result.Stdout, result.Stderr)
+	var truncatedLines int
+	if filter != nil {
+		result.Stdout, truncatedLines = output.Filter(filter, command.Filter.MaxLines, result.Stdout)
+		result.Output = filterOutputLines(filter, command.Filter.MaxLines, result.Output)
+	}
 
 	// Log execution result
 	logEntry.Event = "command_executed"
 	logEntry.ExitCode = result.ExitCode
 	logEntry.ExecutionTime = result.ExecutionTime
+	logEntry.LimitExceeded = result.LimitExceeded
+	logEntry.PeakMemory = result.PeakMemory
+	if result.CPUTime > 0 {
+		logEntry.CPUTime = result.CPUTime.String()
+	}
 	if result.Error != nil {
 		logEntry.Error = result.Error.Error()
 	}
@@ -77,18 +314,197 @@ func (s *Server) Execute(ctx context.Context, req *pb.ExecuteRequest) (*pb.Execu
 
 	// Build response
 	resp := &pb.ExecuteResponse{
-		Success:       result.Error == nil,
-		ExitCode:      int32(result.ExitCode),
-		Stdout:        result.Stdout,
-		Stderr:        result.Stderr,
-		ExecutionTime: result.ExecutionTime,
+		Success:         result.Error == nil,
+		Succeeded:       succeeded,
+		ExitCode:        int32(result.ExitCode),
+		Stdout:          result.Stdout,
+		Stderr:          result.Stderr,
+		ExecutionTime:   result.ExecutionTime,
+		LimitExceeded:   result.LimitExceeded,
+		PeakMemoryBytes: result.PeakMemory,
+		Cached:          cached,
+		TruncatedLines:  int32(truncatedLines),
+	}
+
+	if result.CPUTime > 0 {
+		resp.CpuTime = result.CPUTime.String()
+	}
+	if cached {
+		resp.CacheAge = cacheAge.Round(time.Millisecond).String()
+	}
+	for _, l := range result.Output {
+		resp.Output = append(resp.Output, &pb.OutputLine{
+			Stream:   l.Stream,
+			OffsetNs: int64(l.Offset),
+			Data:     l.Data,
+		})
 	}
 
 	if result.Error != nil {
 		resp.ErrorMessage = result.Error.Error()
+	} else if command.Output != nil {
+		parsed, err := output.Parse(command.Output, result.Stdout)
+		if err != nil {
+			resp.ParseError = err.Error()
+		}
+		resp.Parsed = parsed
+	}
+
+	return resp
+}
+
+// timeError returns the error code and next allowed time of a request
+// rejected for its command's maintenance window or change freezes
+func timeError(err error) (string, string) {
+	var timeErr *validator.TimeError
+	if !errors.As(err, &timeErr) {
+		return "", ""
+	}
+	if timeErr.Next.IsZero() {
+		return timeErr.Code, ""
+	}
+	return timeErr.Code, timeErr.Next.Format(time.RFC3339)
+}
+
+// cacheKey identifies the requests that can share a read-only command's
+// result
+func cacheKey(command string, args []string, outputMode string, timeout int, opts executor.Options) string {
+	// Planned requests may run with other settings than the command's
+	settings, _ := json.Marshal([]any{opts.Limits, opts.Cgroup, opts.Nice, opts.IONice})
+	parts := append([]string{command, outputMode, strconv.Itoa(timeout), string(settings)}, args...)
+	for i, part := range parts {
+		parts[i] = strconv.Quote(part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// executorOptions builds the per-command executor options
+func (s *Server) executorOptions(command *models.Command) executor.Options {
+	nice := command.Nice
+	if nice == nil {
+		nice = s.config.DefaultNice
+	}
+	ionice := command.IONice
+	if ionice == nil {
+		ionice = s.config.DefaultIONice
+	}
+
+	return executor.Options{
+		Limits:   command.Limits,
+		Sandbox:  command.Sandbox,
+		Seccomp:  command.Seccomp,
+		Landlock: command.Landlock,
+
+		Capabilities: command.Capabilities,
+		NoNewPrivs:   command.NoNewPrivs,
+
+		Nice:   nice,
+		IONice: ionice,
+
+		TrimOutput: command.TrimOutput,
+
+		CgroupRoot: s.config.CgroupRoot,
+		Cgroup:     command.Cgroup,
+	}
+}
+
+// redactor builds the redactor for commands from the global rules followed
+// by the commands' own. It returns nil if none have any.
+func (s *Server) redactor(commands ...*models.Command) (*redact.Redactor, error) {
+	redactions := []*models.Redaction{s.config.Redact}
+	for _, command := range commands {
+		redactions = append(redactions, command.Redact)
+	}
+
+	var detectors []string
+	var rules []redact.Rule
+	for _, r := range redactions {
+		if r != nil {
+			detectors = append(detectors, r.Detectors...)
+			rules = append(rules, r.Rules...)
+		}
+	}
+
+	if len(detectors) == 0 && len(rules) == 0 {
+		return nil, nil
+	}
+	return redact.New(detectors, rules)
+}
+
+// redactArgs returns a copy of args for the audit log with secrets redacted
+func redactArgs(redactor *redact.Redactor, args []string) []string {
+	if redactor == nil {
+		return args
+	}
+
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		data, _ := redactor.Redact([]byte(arg))
+		redacted[i] = string(data)
+	}
+	return redacted
+}
+
+// redactOutputLines redacts combined output lines in place, treating the
+// lines of each stream as one text
+func redactOutputLines(redactor *redact.Redactor, lines []executor.OutputLine) {
+	for _, stream := range []string{"stdout", "stderr"} {
+		var indexes []int
+		var data [][]byte
+		for i, l := range lines {
+			if l.Stream == stream {
+				indexes = append(indexes, i)
+				data = append(data, l.Data)
+			}
+		}
+
+		redacted, _ := redactor.RedactLines(data)
+		for j, i := range indexes {
+			lines[i].Data = redacted[j]
+		}
+	}
+}
+
+// outputFilter converts a request's output filter
+func outputFilter(f *pb.OutputFilter) *models.OutputFilter {
+	if f == nil {
+		return nil
+	}
+	return &models.OutputFilter{
+		Head:  int(f.Head),
+		Tail:  int(f.Tail),
+		From:  int(f.From),
+		To:    int(f.To),
+		Match: f.Match,
+		Regex: f.Regex,
+	}
+}
+
+// filterOutputLines applies a filter to the stdout lines of combined output,
+// keeping all stderr lines
+func filterOutputLines(f *models.OutputFilter, maxLines int, lines []executor.OutputLine) []executor.OutputLine {
+	var indexes []int
+	var stdout [][]byte
+	for i, l := range lines {
+		if l.Stream == "stdout" {
+			indexes = append(indexes, i)
+			stdout = append(stdout, l.Data)
+		}
 	}
 
-	return resp, nil
+	keep := make(map[int]bool)
+	selected, _ := output.Select(f, maxLines, stdout)
+	for _, j := range selected {
+		keep[indexes[j]] = true
+	}
+
+	var filtered []executor.OutputLine
+	for i, l := range lines {
+		if l.Stream != "stdout" || keep[i] {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
 }
 
 // logJSON logs an entry in JSON format
@@ -0,0 +1,138 @@
+// Package idempotency remembers the results of requests by their
+// idempotency keys so that retries don't run them again
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMismatch is returned when a key is reused for a different request
+	ErrMismatch = errors.New("idempotency key reused with a different request")
+	// ErrFull is returned when the store holds too many keys
+	ErrFull = errors.New("too many idempotency keys")
+)
+
+// MaxKeys bounds the keys kept at a time
+const MaxKeys = 10000
+
+// Response is a stored response
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Entry is a request that has started with a key
+type Entry struct {
+	fingerprint string
+	expires     time.Time // Zero while the request is in progress
+	done        chan struct{}
+	response    *Response
+}
+
+// Wait waits for the request to finish and returns its response, or nil if
+// it was abandoned and should be started again
+func (e *Entry) Wait(ctx context.Context) (*Response, error) {
+	select {
+	case <-e.done:
+		return e.response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Store keeps finished requests' responses for a time to live
+type Store struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*Entry
+	now     func() time.Time
+}
+
+// New creates a store keeping responses for ttl
+func New(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		entries: make(map[string]*Entry),
+		now:     time.Now,
+	}
+}
+
+// Fingerprint identifies a request by its method, path and body
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin looks up a key. If it's new, Begin reserves it and reports that the
+// caller owns the request, which must then call Finish or Abort. Otherwise
+// it returns the earlier request's entry to wait on.
+func (s *Store) Begin(key, fingerprint string) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if e, ok := s.entries[key]; ok && !s.expired(e, now) {
+		if e.fingerprint != fingerprint {
+			return nil, false, ErrMismatch
+		}
+		return e, false, nil
+	}
+
+	if len(s.entries) >= MaxKeys {
+		for k, e := range s.entries {
+			if s.expired(e, now) {
+				delete(s.entries, k)
+			}
+		}
+		if len(s.entries) >= MaxKeys {
+			return nil, false, ErrFull
+		}
+	}
+
+	e := &Entry{
+		fingerprint: fingerprint,
+		done:        make(chan struct{}),
+	}
+	s.entries[key] = e
+	return e, true, nil
+}
+
+// Finish stores the response of an owned request
+func (s *Store) Finish(key string, response *Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entries[key]
+	e.response = response
+	e.expires = s.now().Add(s.ttl)
+	close(e.done)
+}
+
+// Abort releases an owned request's key without storing a response, so
+// that a retry runs the request again
+func (s *Store) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.entries[key]
+	delete(s.entries, key)
+	close(e.done)
+}
+
+// expired checks whether a finished entry has outlived its time to live
+func (s *Store) expired(e *Entry, now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s := New(time.Hour)
+	s.now = func() time.Time { return now }
+
+	fp := Fingerprint("POST", "/execute", []byte(`{"command":"uptime"}`))
+
+	// The first request owns the key
+	e, owner, err := s.Begin("k1", fp)
+	if err != nil || !owner {
+		t.Fatalf("Begin() = %v, %v, want owner", owner, err)
+	}
+
+	// A retry while it runs waits for it
+	waiter, owner, err := s.Begin("k1", fp)
+	if err != nil || owner || waiter != e {
+		t.Fatalf("Begin() during the request = %v, %v, want the same entry", owner, err)
+	}
+
+	// A different request with the same key is rejected
+	other := Fingerprint("POST", "/execute", []byte(`{"command":"df"}`))
+	if _, _, err := s.Begin("k1", other); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Begin() with a different request error = %v, want ErrMismatch", err)
+	}
+
+	s.Finish("k1", &Response{StatusCode: 200, Body: []byte("ok")})
+	resp, err := waiter.Wait(context.Background())
+	if err != nil || resp == nil || string(resp.Body) != "ok" {
+		t.Fatalf("Wait() = %v, %v", resp, err)
+	}
+
+	// Replays get the stored response until it expires
+	if e, owner, _ := s.Begin("k1", fp); owner || e.response == nil {
+		t.Error("replay within the TTL started a new request")
+	}
+	now = now.Add(2 * time.Hour)
+	if _, owner, _ := s.Begin("k1", fp); !owner {
+		t.Error("request after the TTL didn't start a new request")
+	}
+}
+
+func TestStoreAbort(t *testing.T) {
+	s := New(time.Hour)
+	fp := Fingerprint("POST", "/execute", nil)
+
+	e, _, _ := s.Begin("k1", fp)
+	waiter, _, _ := s.Begin("k1", fp)
+	s.Abort("k1")
+
+	// Waiters learn the request was abandoned and the key is free again
+	if resp, err := waiter.Wait(context.Background()); resp != nil || err != nil {
+		t.Errorf("Wait() after Abort = %v, %v, want nil, nil", resp, err)
+	}
+	if next, owner, _ := s.Begin("k1", fp); !owner || next == e {
+		t.Error("Begin() after Abort didn't start a new request")
+	}
+}
+
+func TestStoreWaitCancelled(t *testing.T) {
+	s := New(time.Hour)
+	e, _, _ := s.Begin("k1", Fingerprint("POST", "/execute", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+}
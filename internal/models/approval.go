@@ -0,0 +1,106 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrApprovalRequired rejects requests that can't be held for approval, such
+// as pipelines with a stage that requires it
+var ErrApprovalRequired = errors.New("command requires approval")
+
+// ApprovalPolicy makes requests for a command wait until other identities
+// approve them
+type ApprovalPolicy struct {
+	// Approvals is the number of approvals needed, 1 if zero
+	Approvals int `yaml:"approvals" json:"approvals"`
+	// Approvers limits who can approve, anyone but the requester if empty
+	Approvers []string `yaml:"approvers" json:"approvers,omitempty"`
+	// Expiry in seconds after which a pending request can no longer be
+	// approved, 3600 if zero
+	Expiry int `yaml:"expiry" json:"expiry,omitempty"`
+}
+
+// Validate checks the approval policy
+func (p *ApprovalPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.Approvals < 0 || p.Expiry < 0 {
+		return fmt.Errorf("approvals and expiry must not be negative")
+	}
+	if len(p.Approvers) > 0 && p.Approvals > len(p.Approvers) {
+		return fmt.Errorf("approvals needs %d approvers but only %d are listed", p.Approvals, len(p.Approvers))
+	}
+	if slices.Contains(p.Approvers, "") {
+		return fmt.Errorf("approvers must not be empty")
+	}
+
+	return nil
+}
+
+// Approval statuses
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved" // Approved and running
+	ApprovalExecuted = "executed"
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// Approval is a request waiting for, or decided by, approvers
+type Approval struct {
+	ID        string         `json:"id"`
+	Command   string         `json:"command"`
+	Args      []string       `json:"args"`
+	Requester string         `json:"requester"`
+	Status    string         `json:"status"`
+	Required  int            `json:"required"`
+	Votes     []ApprovalVote `json:"votes,omitempty"`
+	Created   string         `json:"created"`
+	Expires   string         `json:"expires"`
+	Result    *HTTPResponse  `json:"result,omitempty"`
+}
+
+// ApprovalVote is an approver's decision
+type ApprovalVote struct {
+	Identity string `json:"identity"`
+	Approve  bool   `json:"approve"`
+	Comment  string `json:"comment,omitempty"`
+	Time     string `json:"time"`
+}
+
+// Approval decisions
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+// ApprovalDecision is the body of POST /approvals/{id}
+type ApprovalDecision struct {
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
+
+// Validate checks the decision
+func (d *ApprovalDecision) Validate() error {
+	if d.Decision != DecisionApprove && d.Decision != DecisionReject {
+		return fmt.Errorf("decision must be %s or %s", DecisionApprove, DecisionReject)
+	}
+	return nil
+}
+
+// ApprovalResponse represents the API response for a single approval
+type ApprovalResponse struct {
+	Success  bool      `json:"success"`
+	Approval *Approval `json:"approval,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// ApprovalsResponse represents the API response for GET /approvals
+type ApprovalsResponse struct {
+	Success   bool       `json:"success"`
+	Approvals []Approval `json:"approvals"`
+}
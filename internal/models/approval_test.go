@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestApprovalPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *ApprovalPolicy
+		wantErr bool
+	}{
+		{name: "nil", policy: nil},
+		{name: "defaults", policy: &ApprovalPolicy{}},
+		{name: "listed approvers", policy: &ApprovalPolicy{Approvals: 2, Approvers: []string{"bob", "carol"}, Expiry: 600}},
+		{name: "too few approvers", policy: &ApprovalPolicy{Approvals: 3, Approvers: []string{"bob", "carol"}}, wantErr: true},
+		{name: "empty approver", policy: &ApprovalPolicy{Approvers: []string{"bob", ""}}, wantErr: true},
+		{name: "negative approvals", policy: &ApprovalPolicy{Approvals: -1}, wantErr: true},
+		{name: "negative expiry", policy: &ApprovalPolicy{Expiry: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApprovalDecision_Validate(t *testing.T) {
+	tests := []struct {
+		decision string
+		wantErr  bool
+	}{
+		{decision: DecisionApprove},
+		{decision: DecisionReject},
+		{decision: "", wantErr: true},
+		{decision: "Approve", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.decision, func(t *testing.T) {
+			d := &ApprovalDecision{Decision: tt.decision}
+			if err := d.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// API actions that keys can be scoped to
+const (
+	ActionExecute  = "execute"  // POST /execute
+	ActionPlan     = "plan"     // POST /plan and /apply
+	ActionPipeline = "pipeline" // POST /pipeline
+	ActionRunbook  = "runbook"  // POST /runbooks/{name}
+	ActionApprove  = "approve"  // POST /approvals/{id}
+	ActionRead     = "read"     // GET /schedules, /deliveries and /approvals
+)
+
+var actions = []string{ActionExecute, ActionPlan, ActionPipeline, ActionRunbook, ActionApprove, ActionRead}
+
+// AuthConfig requires requests to the API to present an API key, a JWT, a
+// client certificate or a signature
+type AuthConfig struct {
+	Keys []APIKey `yaml:"keys"`
+	// KeysFile holds more keys, as a YAML list in the same format
+	KeysFile     string              `yaml:"keys_file"`
+	JWT          *JWTConfig          `yaml:"jwt"`
+	Certificates []ClientCertificate `yaml:"certificates"`
+	HMAC         *HMACConfig         `yaml:"hmac"`
+}
+
+// Validate checks the authentication configuration. Keys from KeysFile are
+// validated when they are loaded.
+func (c *AuthConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.Keys) == 0 && c.KeysFile == "" && c.JWT == nil && len(c.Certificates) == 0 && c.HMAC == nil {
+		return fmt.Errorf("no keys, keys_file, jwt, certificates or hmac specified")
+	}
+	if err := c.JWT.Validate(); err != nil {
+		return fmt.Errorf("invalid jwt: %w", err)
+	}
+	if err := c.HMAC.Validate(); err != nil {
+		return fmt.Errorf("invalid hmac: %w", err)
+	}
+	for i, cert := range c.Certificates {
+		if err := cert.Validate(); err != nil {
+			return fmt.Errorf("invalid certificate %d: %w", i+1, err)
+		}
+	}
+	return ValidateAPIKeys(c.Keys)
+}
+
+// Scope lists what a caller may do, "*" for all
+type Scope struct {
+	Actions  []string `yaml:"actions"`
+	Commands []string `yaml:"commands"`
+	Runbooks []string `yaml:"runbooks"`
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	// Name is the caller's identity in logs and approvals
+	Name string
+	Scope
+}
+
+// APIKey is a key that identifies its holder and limits what they can do.
+// Only the key's hash is stored.
+type APIKey struct {
+	// Name is the identity of the key's holder
+	Name string `yaml:"name"`
+	// Hash is "sha256:" followed by the hex encoded SHA-256 of the key
+	Hash  string `yaml:"hash"`
+	Scope `yaml:",inline"`
+}
+
+// ValidateAPIKeys checks keys and that their names and hashes are unique
+func ValidateAPIKeys(keys []APIKey) error {
+	var names, hashes []string
+	for _, key := range keys {
+		if err := key.Validate(); err != nil {
+			return fmt.Errorf("invalid key %s: %w", key.Name, err)
+		}
+		if slices.Contains(names, key.Name) {
+			return fmt.Errorf("duplicate key %s", key.Name)
+		}
+		if slices.Contains(hashes, key.Hash) {
+			return fmt.Errorf("key %s has the same hash as another key", key.Name)
+		}
+		names = append(names, key.Name)
+		hashes = append(hashes, key.Hash)
+	}
+	return nil
+}
+
+// Validate checks the key
+func (k *APIKey) Validate() error {
+	if k.Name == "" {
+		return fmt.Errorf("key name is not specified")
+	}
+
+	digest, ok := strings.CutPrefix(k.Hash, "sha256:")
+	if !ok || len(digest) != 64 || strings.Trim(digest, "0123456789abcdef") != "" {
+		return fmt.Errorf("hash must be sha256: followed by 64 lowercase hex digits")
+	}
+
+	return k.Scope.Validate()
+}
+
+// ClientCertificate grants an identity and a scope to verified client
+// certificates with a common name or a subject alternative name. If both
+// are given, both must match.
+type ClientCertificate struct {
+	Name       string `yaml:"name"`
+	CommonName string `yaml:"common_name"`
+	// SAN is a DNS name, email address, URI or IP address
+	SAN   string `yaml:"san"`
+	Scope `yaml:",inline"`
+}
+
+// Validate checks the certificate rule
+func (c *ClientCertificate) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("certificate name is not specified")
+	}
+	if c.CommonName == "" && c.SAN == "" {
+		return fmt.Errorf("common_name or san must be specified")
+	}
+	return c.Scope.Validate()
+}
+
+// Validate checks the scope
+func (s *Scope) Validate() error {
+	if len(s.Actions) == 0 {
+		return fmt.Errorf("no actions specified")
+	}
+	for _, action := range s.Actions {
+		if action != "*" && !slices.Contains(actions, action) {
+			return fmt.Errorf("unknown action %s", action)
+		}
+	}
+	return nil
+}
+
+// Add adds what another scope allows to the scope
+func (s *Scope) Add(other Scope) {
+	s.Actions = append(s.Actions, other.Actions...)
+	s.Commands = append(s.Commands, other.Commands...)
+	s.Runbooks = append(s.Runbooks, other.Runbooks...)
+}
+
+// Allows reports whether the scope allows an action
+func (s *Scope) Allows(action string) bool {
+	return slices.Contains(s.Actions, "*") || slices.Contains(s.Actions, action)
+}
+
+// AllowsCommand reports whether the scope allows running a command
+func (s *Scope) AllowsCommand(command string) bool {
+	return slices.Contains(s.Commands, "*") || slices.Contains(s.Commands, command)
+}
+
+// AllowsRunbook reports whether the scope allows running a runbook
+func (s *Scope) AllowsRunbook(runbook string) bool {
+	return slices.Contains(s.Runbooks, "*") || slices.Contains(s.Runbooks, runbook)
+}
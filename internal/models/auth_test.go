@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	hash := "sha256:" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name    string
+		key     APIKey
+		wantErr bool
+	}{
+		{name: "valid", key: APIKey{Name: "ci", Hash: hash, Scope: Scope{Actions: []string{ActionExecute, ActionRead}}}},
+		{name: "all actions", key: APIKey{Name: "ci", Hash: hash, Scope: Scope{Actions: []string{"*"}}}},
+		{name: "no name", key: APIKey{Hash: hash, Scope: Scope{Actions: []string{"*"}}}, wantErr: true},
+		{name: "plain key", key: APIKey{Name: "ci", Hash: "secret", Scope: Scope{Actions: []string{"*"}}}, wantErr: true},
+		{name: "uppercase hash", key: APIKey{Name: "ci", Hash: "sha256:" + strings.Repeat("AB", 32), Scope: Scope{Actions: []string{"*"}}}, wantErr: true},
+		{name: "no actions", key: APIKey{Name: "ci", Hash: hash}, wantErr: true},
+		{name: "unknown action", key: APIKey{Name: "ci", Hash: hash, Scope: Scope{Actions: []string{"delete"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScope_Allows(t *testing.T) {
+	scope := Scope{
+		Actions:  []string{ActionExecute},
+		Commands: []string{"uptime", "df"},
+		Runbooks: []string{"*"},
+	}
+
+	if !scope.Allows(ActionExecute) || scope.Allows(ActionApprove) {
+		t.Error("Allows() doesn't follow the scope's actions")
+	}
+	if !scope.AllowsCommand("df") || scope.AllowsCommand("systemctl") {
+		t.Error("AllowsCommand() doesn't follow the scope's commands")
+	}
+	if !scope.AllowsRunbook("restart-nginx") {
+		t.Error("AllowsRunbook() doesn't allow every runbook for *")
+	}
+
+	scope.Add(Scope{Actions: []string{ActionApprove}, Commands: []string{"systemctl"}})
+	if !scope.Allows(ActionApprove) || !scope.AllowsCommand("systemctl") || !scope.AllowsCommand("df") {
+		t.Error("Add() doesn't add the other scope")
+	}
+}
+
+func TestClientCertificate_Validate(t *testing.T) {
+	scope := Scope{Actions: []string{ActionRead}}
+
+	tests := []struct {
+		name    string
+		cert    ClientCertificate
+		wantErr bool
+	}{
+		{name: "common name", cert: ClientCertificate{Name: "ops", CommonName: "ops.example.com", Scope: scope}},
+		{name: "SAN", cert: ClientCertificate{Name: "deploy", SAN: "spiffe://example.com/deploy", Scope: scope}},
+		{name: "no name", cert: ClientCertificate{CommonName: "ops.example.com", Scope: scope}, wantErr: true},
+		{name: "nothing to match", cert: ClientCertificate{Name: "ops", Scope: scope}, wantErr: true},
+		{name: "no actions", cert: ClientCertificate{Name: "ops", CommonName: "ops.example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cert.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// HMACConfig accepts requests to /execute signed with per-client shared
+// secrets, for callers that can't use client certificates
+type HMACConfig struct {
+	// MaxSkew is how many seconds a request's timestamp may differ from
+	// the API's clock, 300 if zero
+	MaxSkew int          `yaml:"max_skew"`
+	Clients []HMACClient `yaml:"clients"`
+}
+
+// HMACClient is a caller that signs its requests
+type HMACClient struct {
+	// Name identifies the client in requests, logs and approvals
+	Name string `yaml:"name"`
+	// Secret signs the requests, read from SecretFile if that is set
+	Secret     string `yaml:"secret"`
+	SecretFile string `yaml:"secret_file"`
+	// Commands the client may execute, "*" for all
+	Commands []string `yaml:"commands"`
+}
+
+// Validate checks the HMAC configuration
+func (c *HMACConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MaxSkew < 0 {
+		return fmt.Errorf("max_skew must not be negative")
+	}
+	if len(c.Clients) == 0 {
+		return fmt.Errorf("no clients specified")
+	}
+	var names []string
+	for _, client := range c.Clients {
+		if client.Name == "" {
+			return fmt.Errorf("client name is not specified")
+		}
+		if slices.Contains(names, client.Name) {
+			return fmt.Errorf("duplicate client %s", client.Name)
+		}
+		if (client.Secret == "") == (client.SecretFile == "") {
+			return fmt.Errorf("client %s: exactly one of secret and secret_file must be specified", client.Name)
+		}
+		names = append(names, client.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestHMACConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *HMACConfig
+		wantErr bool
+	}{
+		{name: "none", config: nil},
+		{name: "valid", config: &HMACConfig{Clients: []HMACClient{{Name: "ci", SecretFile: "ci.key", Commands: []string{"uptime"}}}}},
+		{name: "no clients", config: &HMACConfig{}, wantErr: true},
+		{name: "negative skew", config: &HMACConfig{MaxSkew: -1, Clients: []HMACClient{{Name: "ci", Secret: "s"}}}, wantErr: true},
+		{name: "no name", config: &HMACConfig{Clients: []HMACClient{{Secret: "s"}}}, wantErr: true},
+		{name: "no secret", config: &HMACConfig{Clients: []HMACClient{{Name: "ci"}}}, wantErr: true},
+		{name: "two secrets", config: &HMACConfig{Clients: []HMACClient{{Name: "ci", Secret: "s", SecretFile: "ci.key"}}}, wantErr: true},
+		{
+			name:    "duplicate",
+			config:  &HMACConfig{Clients: []HMACClient{{Name: "ci", Secret: "a"}, {Name: "ci", Secret: "b"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+)
+
+// JWTConfig accepts JWTs from an identity provider as bearer tokens
+type JWTConfig struct {
+	// Issuer and Audience must match the token's iss and aud claims
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// JWKSFile is a JSON Web Key Set of the provider's signing keys. It is
+	// read again when a token names a key it doesn't have.
+	JWKSFile string `yaml:"jwks_file"`
+	// PublicKeys are PEM files of more signing keys
+	PublicKeys []string `yaml:"public_keys"`
+	// ClockSkew is how many seconds exp and nbf may be off by
+	ClockSkew int `yaml:"clock_skew"`
+	// IdentityClaim names the claim with the caller's identity, sub if
+	// empty
+	IdentityClaim string `yaml:"identity_claim"`
+	// Rules grant callers scopes by their claims
+	Rules []ClaimRule `yaml:"rules"`
+}
+
+// ClaimRule grants a scope to tokens whose claim has a value. The claim
+// may be a path into nested objects such as "realm_access.roles". String
+// claims are taken as a space separated list, as scope is.
+type ClaimRule struct {
+	Claim string `yaml:"claim"`
+	Value string `yaml:"value"`
+	Scope `yaml:",inline"`
+}
+
+// Validate checks the JWT configuration
+func (c *JWTConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Issuer == "" {
+		return fmt.Errorf("issuer is not specified")
+	}
+	if c.Audience == "" {
+		return fmt.Errorf("audience is not specified")
+	}
+	if c.JWKSFile == "" && len(c.PublicKeys) == 0 {
+		return fmt.Errorf("no jwks_file or public_keys specified")
+	}
+	if c.ClockSkew < 0 {
+		return fmt.Errorf("clock_skew must not be negative")
+	}
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("no rules specified")
+	}
+	for i, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the rule
+func (r *ClaimRule) Validate() error {
+	if r.Claim == "" {
+		return fmt.Errorf("claim is not specified")
+	}
+	if r.Value == "" {
+		return fmt.Errorf("value is not specified")
+	}
+	return r.Scope.Validate()
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestJWTConfig_Validate(t *testing.T) {
+	rule := ClaimRule{Claim: "groups", Value: "sre", Scope: Scope{Actions: []string{"*"}}}
+
+	tests := []struct {
+		name    string
+		config  JWTConfig
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: JWTConfig{Issuer: "https://idp", Audience: "sevalet", JWKSFile: "jwks.json", Rules: []ClaimRule{rule}},
+		},
+		{
+			name:    "no issuer",
+			config:  JWTConfig{Audience: "sevalet", JWKSFile: "jwks.json", Rules: []ClaimRule{rule}},
+			wantErr: true,
+		},
+		{
+			name:    "no audience",
+			config:  JWTConfig{Issuer: "https://idp", JWKSFile: "jwks.json", Rules: []ClaimRule{rule}},
+			wantErr: true,
+		},
+		{
+			name:    "no keys",
+			config:  JWTConfig{Issuer: "https://idp", Audience: "sevalet", Rules: []ClaimRule{rule}},
+			wantErr: true,
+		},
+		{
+			name:    "negative skew",
+			config:  JWTConfig{Issuer: "https://idp", Audience: "sevalet", PublicKeys: []string{"idp.pem"}, ClockSkew: -1, Rules: []ClaimRule{rule}},
+			wantErr: true,
+		},
+		{
+			name:    "no rules",
+			config:  JWTConfig{Issuer: "https://idp", Audience: "sevalet", JWKSFile: "jwks.json"},
+			wantErr: true,
+		},
+		{
+			name: "rule without value",
+			config: JWTConfig{Issuer: "https://idp", Audience: "sevalet", JWKSFile: "jwks.json", Rules: []ClaimRule{
+				{Claim: "groups", Scope: Scope{Actions: []string{"*"}}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
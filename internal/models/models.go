@@ -1,16 +1,112 @@
 package models
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
+	"slices"
+	"unicode/utf8"
 )
 
 // Command represents an allowed command with its arguments
 type Command struct {
-	Name        string   `yaml:"name" json:"name"`
-	Description string   `yaml:"description" json:"description"`
-	AllowedArgs []string `yaml:"allowed_args" json:"allowed_args"`
+	Name         string          `yaml:"name" json:"name"`
+	Description  string          `yaml:"description" json:"description"`
+	AllowedArgs  []string        `yaml:"allowed_args" json:"allowed_args"`
+	Limits       *ResourceLimits `yaml:"limits" json:"limits,omitempty"`
+	Cgroup       *CgroupLimits   `yaml:"cgroup" json:"cgroup,omitempty"`
+	Sandbox      *Sandbox        `yaml:"sandbox" json:"sandbox,omitempty"`
+	Seccomp      *Seccomp        `yaml:"seccomp" json:"seccomp,omitempty"`
+	Landlock     *Landlock       `yaml:"landlock" json:"landlock,omitempty"`
+	Capabilities *Capabilities   `yaml:"capabilities" json:"capabilities,omitempty"`
+	NoNewPrivs   bool            `yaml:"no_new_privs" json:"no_new_privs,omitempty"`
+	Nice         *int            `yaml:"nice" json:"nice,omitempty"`
+	IONice       *IOPriority     `yaml:"ionice" json:"ionice,omitempty"`
+	TrimOutput   bool            `yaml:"trim_output" json:"trim_output,omitempty"`
+	OutputMode   string          `yaml:"output_mode" json:"output_mode,omitempty"`
+	Output       *OutputParser   `yaml:"output" json:"output,omitempty"`
+	Redact       *Redaction      `yaml:"redact" json:"redact,omitempty"`
+	Filter       *FilterPolicy   `yaml:"filter" json:"filter,omitempty"`
+
+	// Success criteria decide whether a completed run succeeded from the
+	// caller's point of view. By default only exit code 0 does.
+	SuccessExitCodes   []int  `yaml:"success_exit_codes" json:"success_exit_codes,omitempty"`
+	SuccessStdoutMatch string `yaml:"success_stdout_match" json:"success_stdout_match,omitempty"`
+	SuccessStderrMatch string `yaml:"success_stderr_match" json:"success_stderr_match,omitempty"`
+
+	// ReadOnly marks commands that don't change the system. Their results
+	// can be shared by identical requests for CacheTTL seconds.
+	ReadOnly bool `yaml:"read_only" json:"read_only,omitempty"`
+	CacheTTL int  `yaml:"cache_ttl" json:"cache_ttl,omitempty"`
+
+	// RequiresApproval holds requests until other identities approve them
+	RequiresApproval *ApprovalPolicy `yaml:"requires_approval" json:"requires_approval,omitempty"`
+
+	// Window restricts the command to a maintenance window, and Freezes
+	// reject it during change freezes, both by name
+	Window  string   `yaml:"window" json:"window,omitempty"`
+	Freezes []string `yaml:"freezes" json:"freezes,omitempty"`
+}
+
+// ValidateCache checks that only read-only commands are cached
+func (c *Command) ValidateCache() error {
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl must not be negative")
+	}
+	if c.CacheTTL > 0 && !c.ReadOnly {
+		return fmt.Errorf("cache_ttl requires read_only")
+	}
+	return nil
+}
+
+// ValidateSuccess checks the success criteria
+func (c *Command) ValidateSuccess() error {
+	for _, code := range c.SuccessExitCodes {
+		if code < 0 || code > 255 {
+			return fmt.Errorf("success exit codes must be between 0 and 255")
+		}
+	}
+	if _, err := regexp.Compile(c.SuccessStdoutMatch); err != nil {
+		return fmt.Errorf("invalid success_stdout_match: %w", err)
+	}
+	if _, err := regexp.Compile(c.SuccessStderrMatch); err != nil {
+		return fmt.Errorf("invalid success_stderr_match: %w", err)
+	}
+	return nil
+}
+
+// SuccessExitCode reports whether exitCode is one of the command's success
+// exit codes
+func (c *Command) SuccessExitCode(exitCode int) bool {
+	if len(c.SuccessExitCodes) == 0 {
+		return exitCode == 0
+	}
+	return slices.Contains(c.SuccessExitCodes, exitCode)
+}
+
+// Succeeded reports whether a run that completed with the given exit code
+// and output meets the command's success criteria
+func (c *Command) Succeeded(exitCode int, stdout, stderr []byte) bool {
+	if !c.SuccessExitCode(exitCode) {
+		return false
+	}
+
+	if c.SuccessStdoutMatch != "" {
+		re, err := regexp.Compile(c.SuccessStdoutMatch)
+		if err != nil || !re.Match(stdout) {
+			return false
+		}
+	}
+	if c.SuccessStderrMatch != "" {
+		re, err := regexp.Compile(c.SuccessStderrMatch)
+		if err != nil || !re.Match(stderr) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // CommandList contains all allowed commands
@@ -38,11 +134,100 @@ func (c *Command) IsArgAllowed(arg string) bool {
 	return false
 }
 
+// Output modes select how stdout and stderr are captured
+const (
+	// OutputModeSeparate captures stdout and stderr in separate buffers
+	OutputModeSeparate = "separate"
+	// OutputModeCombined additionally records an ordered stream of lines
+	// from both, tagged with their source and time since start
+	OutputModeCombined = "combined"
+)
+
+// ValidateOutputMode checks that mode is empty or a known output mode
+func ValidateOutputMode(mode string) error {
+	switch mode {
+	case "", OutputModeSeparate, OutputModeCombined:
+		return nil
+	default:
+		return fmt.Errorf("output_mode must be %s or %s", OutputModeSeparate, OutputModeCombined)
+	}
+}
+
 // ExecuteRequest represents an HTTP request to execute a command
 type ExecuteRequest struct {
-	Command string   `json:"command"`
-	Args    []string `json:"args"`
-	Timeout int      `json:"timeout"`
+	Command    string        `json:"command"`
+	Args       []string      `json:"args"`
+	Timeout    int           `json:"timeout"`
+	OutputMode string        `json:"output_mode,omitempty"`
+	Filter     *OutputFilter `json:"filter,omitempty"`
+}
+
+// OutputFilter selects the lines of stdout to return. Lines are first
+// limited to the range From to To (1-based, inclusive), then matched
+// against Match or Regex and finally limited to the first Head or last Tail
+// lines.
+type OutputFilter struct {
+	Head  int    `json:"head,omitempty"`
+	Tail  int    `json:"tail,omitempty"`
+	From  int    `json:"from,omitempty"`
+	To    int    `json:"to,omitempty"`
+	Match string `json:"match,omitempty"` // Fixed string
+	Regex string `json:"regex,omitempty"`
+}
+
+// Filter kinds, as allowed by FilterPolicy
+const (
+	FilterHead  = "head"
+	FilterTail  = "tail"
+	FilterLines = "lines"
+	FilterMatch = "match"
+	FilterRegex = "regex"
+)
+
+// Kinds returns the kinds of filtering f uses
+func (f *OutputFilter) Kinds() []string {
+	var kinds []string
+	if f.Head > 0 {
+		kinds = append(kinds, FilterHead)
+	}
+	if f.Tail > 0 {
+		kinds = append(kinds, FilterTail)
+	}
+	if f.From > 0 || f.To > 0 {
+		kinds = append(kinds, FilterLines)
+	}
+	if f.Match != "" {
+		kinds = append(kinds, FilterMatch)
+	}
+	if f.Regex != "" {
+		kinds = append(kinds, FilterRegex)
+	}
+	return kinds
+}
+
+// Validate checks that the filter is consistent
+func (f *OutputFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	if f.Head < 0 || f.Tail < 0 || f.From < 0 || f.To < 0 {
+		return fmt.Errorf("filter values must not be negative")
+	}
+	if f.Head > 0 && f.Tail > 0 {
+		return fmt.Errorf("filter can't set both head and tail")
+	}
+	if f.To > 0 && f.From > f.To {
+		return fmt.Errorf("filter from must not be after to")
+	}
+	if f.Match != "" && f.Regex != "" {
+		return fmt.Errorf("filter can't set both match and regex")
+	}
+	if _, err := regexp.Compile(f.Regex); err != nil {
+		return fmt.Errorf("invalid filter regex: %w", err)
+	}
+
+	return nil
 }
 
 // NewExecuteRequestFromJSON creates a request from JSON body
@@ -76,33 +261,208 @@ func (r *ExecuteRequest) Validate() error {
 		return fmt.Errorf("timeout must be 300 seconds or less")
 	}
 
-	return nil
+	if err := ValidateOutputMode(r.OutputMode); err != nil {
+		return err
+	}
+
+	return r.Filter.Validate()
 }
 
 // HTTPResponse represents the API response
 type HTTPResponse struct {
-	Success       bool   `json:"success"`
-	ExitCode      int    `json:"exit_code,omitempty"`
-	Stdout        string `json:"stdout,omitempty"`
+	Success        bool            `json:"success"`
+	Succeeded      bool            `json:"succeeded"`
+	ExitCode       int             `json:"exit_code,omitempty"`
+	Stdout         string          `json:"stdout,omitempty"`
+	Stderr         string          `json:"stderr,omitempty"`
+	Encoding       string          `json:"encoding,omitempty"`
+	Output         []OutputLine    `json:"output,omitempty"`
+	Parsed         json.RawMessage `json:"parsed,omitempty"`
+	ParseError     string          `json:"parse_error,omitempty"`
+	ExecutionTime  string          `json:"execution_time,omitempty"`
+	LimitExceeded  string          `json:"limit_exceeded,omitempty"`
+	PeakMemory     uint64          `json:"peak_memory_bytes,omitempty"`
+	CPUTime        string          `json:"cpu_time,omitempty"`
+	Cached         bool            `json:"cached,omitempty"`
+	CacheAge       string          `json:"cache_age,omitempty"`
+	TruncatedLines int             `json:"truncated_lines,omitempty"` // Filtered lines beyond max_lines
+	Error          string          `json:"error,omitempty"`
+	ErrorCode      string          `json:"error_code,omitempty"`
+	NextAllowed    string          `json:"next_allowed,omitempty"`
+}
+
+// OutputLine is a line of combined output
+type OutputLine struct {
+	Stream   string `json:"stream"`    // stdout or stderr
+	OffsetNs int64  `json:"offset_ns"` // Monotonic time since the command started
+	Line     string `json:"line"`
+}
+
+// SetOutput stores the command output in the response. lines hold the raw
+// bytes of the combined output, if any. Output that is not valid UTF-8 is
+// base64 encoded, in which case Encoding is set to "base64" and applies to
+// stdout, stderr and every line.
+func (r *HTTPResponse) SetOutput(stdout, stderr []byte, lines []OutputLine) {
+	valid := utf8.Valid(stdout) && utf8.Valid(stderr)
+	for _, l := range lines {
+		valid = valid && utf8.ValidString(l.Line)
+	}
+
+	r.Output = lines
+	if valid {
+		r.Stdout = string(stdout)
+		r.Stderr = string(stderr)
+		r.Encoding = ""
+		return
+	}
+
+	r.Stdout = base64.StdEncoding.EncodeToString(stdout)
+	r.Stderr = base64.StdEncoding.EncodeToString(stderr)
+	for i := range r.Output {
+		r.Output[i].Line = base64.StdEncoding.EncodeToString([]byte(r.Output[i].Line))
+	}
+	r.Encoding = "base64"
+}
+
+// MaxPipelineStages is the maximum number of stages in a pipeline
+const MaxPipelineStages = 10
+
+// PipelineStage is a command in a pipeline request
+type PipelineStage struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// PipelineRequest represents an HTTP request to execute a pipeline, in
+// which each stage's stdout is connected to the next stage's stdin
+type PipelineRequest struct {
+	Stages  []PipelineStage `json:"stages"`
+	Timeout int             `json:"timeout"`
+}
+
+// NewPipelineRequestFromJSON creates a pipeline request from JSON body
+func NewPipelineRequestFromJSON(body io.Reader) (*PipelineRequest, error) {
+	var req PipelineRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON request: %w", err)
+	}
+
+	// Set default timeout
+	if req.Timeout <= 0 {
+		req.Timeout = 30
+	}
+
+	// Initialize args if nil
+	for i := range req.Stages {
+		if req.Stages[i].Args == nil {
+			req.Stages[i].Args = []string{}
+		}
+	}
+
+	return &req, nil
+}
+
+// Validate performs basic validation on the pipeline request
+func (r *PipelineRequest) Validate() error {
+	if len(r.Stages) == 0 {
+		return fmt.Errorf("pipeline has no stages")
+	}
+	if len(r.Stages) > MaxPipelineStages {
+		return fmt.Errorf("pipeline must have %d stages or less", MaxPipelineStages)
+	}
+
+	for i, stage := range r.Stages {
+		if stage.Command == "" {
+			return fmt.Errorf("command is not specified for stage %d", i+1)
+		}
+	}
+
+	// Enforce maximum timeout
+	if r.Timeout > 300 {
+		return fmt.Errorf("timeout must be 300 seconds or less")
+	}
+
+	return nil
+}
+
+// PipelineResponse represents the API response for a pipeline
+type PipelineResponse struct {
+	Success       bool            `json:"success"`
+	Succeeded     bool            `json:"succeeded"`
+	Stdout        string          `json:"stdout,omitempty"`
+	Encoding      string          `json:"encoding,omitempty"`
+	Stages        []StageResponse `json:"stages,omitempty"`
+	ExecutionTime string          `json:"execution_time,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ErrorCode     string          `json:"error_code,omitempty"`
+	NextAllowed   string          `json:"next_allowed,omitempty"`
+}
+
+// StageResponse reports how a pipeline stage exited
+type StageResponse struct {
+	Command       string `json:"command"`
+	ExitCode      int    `json:"exit_code"`
+	Signal        string `json:"signal,omitempty"`
+	Succeeded     bool   `json:"succeeded"`
 	Stderr        string `json:"stderr,omitempty"`
-	ExecutionTime string `json:"execution_time,omitempty"`
+	LimitExceeded string `json:"limit_exceeded,omitempty"`
+	PeakMemory    uint64 `json:"peak_memory_bytes,omitempty"`
+	CPUTime       string `json:"cpu_time,omitempty"`
 	Error         string `json:"error,omitempty"`
 }
 
+// SetOutput stores the pipeline's stdout and the stderr of each stage,
+// which must already be present in Stages. As with HTTPResponse.SetOutput,
+// all of them are base64 encoded if any is not valid UTF-8.
+func (r *PipelineResponse) SetOutput(stdout []byte, stderr [][]byte) {
+	valid := utf8.Valid(stdout)
+	for _, s := range stderr {
+		valid = valid && utf8.Valid(s)
+	}
+
+	encode := func(b []byte) string { return string(b) }
+	r.Encoding = ""
+	if !valid {
+		encode = base64.StdEncoding.EncodeToString
+		r.Encoding = "base64"
+	}
+
+	r.Stdout = encode(stdout)
+	for i := range r.Stages {
+		r.Stages[i].Stderr = encode(stderr[i])
+	}
+}
+
 // LogEntry represents a structured log entry
 type LogEntry struct {
-	Timestamp     string   `json:"timestamp"`
-	Level         string   `json:"level"`
-	Mode          string   `json:"mode"`
-	Event         string   `json:"event"`
-	Command       string   `json:"command,omitempty"`
-	Args          []string `json:"args,omitempty"`
-	ExitCode      int      `json:"exit_code,omitempty"`
-	ExecutionTime string   `json:"execution_time,omitempty"`
-	Method        string   `json:"method,omitempty"`
-	Path          string   `json:"path,omitempty"`
-	RemoteAddr    string   `json:"remote_addr,omitempty"`
-	Status        int      `json:"status,omitempty"`
-	Latency       string   `json:"latency,omitempty"`
-	Error         string   `json:"error,omitempty"`
+	Timestamp     string          `json:"timestamp"`
+	Level         string          `json:"level"`
+	Mode          string          `json:"mode"`
+	Event         string          `json:"event"`
+	Command       string          `json:"command,omitempty"`
+	Args          []string        `json:"args,omitempty"`
+	Stages        []PipelineStage `json:"stages,omitempty"`
+	Runbook       string          `json:"runbook,omitempty"`
+	Step          string          `json:"step,omitempty"`
+	Schedule      string          `json:"schedule,omitempty"`
+	Delivery      string          `json:"delivery,omitempty"`
+	Approval      string          `json:"approval,omitempty"`
+	Plan          string          `json:"plan,omitempty"`
+	Identity      string          `json:"identity,omitempty"`
+	URL           string          `json:"url,omitempty"`
+	Attempt       int             `json:"attempt,omitempty"`
+	ExitCode      int             `json:"exit_code,omitempty"`
+	ExitCodes     []int           `json:"exit_codes,omitempty"`
+	ExecutionTime string          `json:"execution_time,omitempty"`
+	LimitExceeded string          `json:"limit_exceeded,omitempty"`
+	PeakMemory    uint64          `json:"peak_memory_bytes,omitempty"`
+	CPUTime       string          `json:"cpu_time,omitempty"`
+	Redactions    int             `json:"redactions,omitempty"`
+	Cached        bool            `json:"cached,omitempty"`
+	Method        string          `json:"method,omitempty"`
+	Path          string          `json:"path,omitempty"`
+	RemoteAddr    string          `json:"remote_addr,omitempty"`
+	Status        int             `json:"status,omitempty"`
+	Latency       string          `json:"latency,omitempty"`
+	Error         string          `json:"error,omitempty"`
 }
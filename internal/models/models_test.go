@@ -1,8 +1,11 @@
 package models
 
 import (
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/zinrai/sevalet/internal/redact"
 )
 
 func TestExecuteRequest_Validate(t *testing.T) {
@@ -86,6 +89,53 @@ func TestExecuteRequest_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "combined output mode",
+			request: &ExecuteRequest{
+				Command:    "ls",
+				Timeout:    30,
+				OutputMode: OutputModeCombined,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid filter",
+			request: &ExecuteRequest{
+				Command: "cat",
+				Timeout: 30,
+				Filter:  &OutputFilter{Tail: 100, Match: "error"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter with head and tail",
+			request: &ExecuteRequest{
+				Command: "cat",
+				Timeout: 30,
+				Filter:  &OutputFilter{Head: 10, Tail: 10},
+			},
+			wantErr: true,
+			errMsg:  "filter can't set both head and tail",
+		},
+		{
+			name: "filter with invalid regex",
+			request: &ExecuteRequest{
+				Command: "cat",
+				Timeout: 30,
+				Filter:  &OutputFilter{Regex: "("},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown output mode",
+			request: &ExecuteRequest{
+				Command:    "ls",
+				Timeout:    30,
+				OutputMode: "interleaved",
+			},
+			wantErr: true,
+			errMsg:  "output_mode must be separate or combined",
+		},
 	}
 
 	for _, tt := range tests {
@@ -201,3 +251,637 @@ func TestNewExecuteRequestFromJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ByteSize
+		wantErr bool
+	}{
+		{input: "4096", want: 4096},
+		{input: "4096B", want: 4096},
+		{input: "512K", want: 512 << 10},
+		{input: "64MiB", want: 64 << 20},
+		{input: "64mb", want: 64 << 20},
+		{input: "1G", want: 1 << 30},
+		{input: "2T", want: 2 << 40},
+		{input: "", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "1.5G", wantErr: true},
+		{input: "10i", wantErr: true},
+		{input: "10P", wantErr: true},
+		{input: "99999999999999T", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseByteSize(%q) expected error but got %d", tt.input, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("ParseByteSize(%q) unexpected error = %v", tt.input, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseByteSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  *ResourceLimits
+		wantErr bool
+	}{
+		{name: "nil limits", limits: nil},
+		{name: "empty limits", limits: &ResourceLimits{}},
+		{name: "all limits set", limits: &ResourceLimits{CPUSeconds: 10, AddressSpace: 512 << 20, OpenFiles: 256, Processes: 64, FileSize: 1 << 20}},
+		{name: "address space too small", limits: &ResourceLimits{AddressSpace: 1 << 20}, wantErr: true},
+		{name: "too few open files", limits: &ResourceLimits{OpenFiles: 3}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCgroupLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  *CgroupLimits
+		wantErr bool
+	}{
+		{name: "nil limits", limits: nil},
+		{name: "all limits set", limits: &CgroupLimits{MemoryMax: 256 << 20, CPUMax: "50000 100000", PidsMax: 32}},
+		{name: "cpu max without period", limits: &CgroupLimits{CPUMax: "50000"}},
+		{name: "unlimited cpu", limits: &CgroupLimits{CPUMax: "max 100000"}},
+		{name: "memory too small", limits: &CgroupLimits{MemoryMax: 4096}, wantErr: true},
+		{name: "cpu max percentage", limits: &CgroupLimits{CPUMax: "50%"}, wantErr: true},
+		{name: "cpu max zero", limits: &CgroupLimits{CPUMax: "0 100000"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCgroupLimits_Controllers(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits *CgroupLimits
+		want   []string
+	}{
+		{name: "nil limits", limits: nil},
+		{name: "all limits set", limits: &CgroupLimits{MemoryMax: 256 << 20, CPUMax: "50000 100000", PidsMax: 32}, want: []string{"memory", "cpu", "pids"}},
+		{name: "pids only", limits: &CgroupLimits{PidsMax: 32}, want: []string{"pids"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.Controllers(); !slices.Equal(got, tt.want) {
+				t.Errorf("Controllers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSandbox_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox *Sandbox
+		wantErr bool
+	}{
+		{name: "nil sandbox", sandbox: nil},
+		{name: "empty sandbox", sandbox: &Sandbox{}},
+		{
+			name: "binds and tmpfs",
+			sandbox: &Sandbox{
+				Binds: []BindMount{{Source: "/var/log"}, {Source: "/srv/data", Target: "/data", Writable: true}},
+				Tmpfs: []string{"/tmp"},
+			},
+		},
+		{name: "relative bind source", sandbox: &Sandbox{Binds: []BindMount{{Source: "var/log"}}}, wantErr: true},
+		{name: "unclean bind target", sandbox: &Sandbox{Binds: []BindMount{{Source: "/var/log", Target: "/data/../etc"}}}, wantErr: true},
+		{name: "root tmpfs", sandbox: &Sandbox{Tmpfs: []string{"/"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sandbox.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeccomp_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		seccomp *Seccomp
+		wantErr bool
+	}{
+		{name: "nil seccomp", seccomp: nil},
+		{name: "deny list", seccomp: &Seccomp{Deny: []string{"ptrace", "@network"}}},
+		{name: "allow list", seccomp: &Seccomp{Allow: []string{"read", "write", "exit_group"}}},
+		{name: "neither list", seccomp: &Seccomp{}, wantErr: true},
+		{name: "both lists", seccomp: &Seccomp{Allow: []string{"read"}, Deny: []string{"ptrace"}}, wantErr: true},
+		{name: "execve denied", seccomp: &Seccomp{Deny: []string{"execve"}}, wantErr: true},
+		{name: "unknown system call", seccomp: &Seccomp{Deny: []string{"no_such_syscall"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.seccomp.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedaction_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction *Redaction
+		wantErr   bool
+	}{
+		{name: "nil redaction", redaction: nil},
+		{name: "detectors and rules", redaction: &Redaction{Detectors: []string{"jwt"}, Rules: []redact.Rule{{Pattern: `secret=\S+`}}}},
+		{name: "unknown detector", redaction: &Redaction{Detectors: []string{"ssn"}}, wantErr: true},
+		{name: "invalid rule", redaction: &Redaction{Rules: []redact.Rule{{Pattern: `[`}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.redaction.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *OutputFilter
+		wantErr bool
+	}{
+		{name: "nil filter", filter: nil},
+		{name: "range", filter: &OutputFilter{From: 10, To: 20}},
+		{name: "open range", filter: &OutputFilter{From: 10}},
+		{name: "negative head", filter: &OutputFilter{Head: -1}, wantErr: true},
+		{name: "from after to", filter: &OutputFilter{From: 20, To: 10}, wantErr: true},
+		{name: "match and regex", filter: &OutputFilter{Match: "a", Regex: "b"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *FilterPolicy
+		wantErr bool
+	}{
+		{name: "nil policy", policy: nil},
+		{name: "allow all", policy: &FilterPolicy{}},
+		{name: "known kinds", policy: &FilterPolicy{Allow: []string{"tail", "match"}, MaxLines: 500}},
+		{name: "unknown kind", policy: &FilterPolicy{Allow: []string{"sort"}}, wantErr: true},
+		{name: "negative max lines", policy: &FilterPolicy{MaxLines: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterPolicy_Check(t *testing.T) {
+	policy := &FilterPolicy{Allow: []string{"tail", "lines", "match"}, MaxLines: 100, MaxPatternLength: 8}
+
+	tests := []struct {
+		name    string
+		policy  *FilterPolicy
+		filter  *OutputFilter
+		wantErr bool
+	}{
+		{name: "no filter without policy", policy: nil, filter: nil},
+		{name: "filter without policy", policy: nil, filter: &OutputFilter{Tail: 10}, wantErr: true},
+		{name: "allowed kinds", policy: policy, filter: &OutputFilter{Tail: 100, Match: "error"}},
+		{name: "kind not allowed", policy: policy, filter: &OutputFilter{Head: 10}, wantErr: true},
+		{name: "tail over max lines", policy: policy, filter: &OutputFilter{Tail: 101}, wantErr: true},
+		{name: "range over max lines", policy: policy, filter: &OutputFilter{From: 1, To: 101}, wantErr: true},
+		{name: "range within max lines", policy: policy, filter: &OutputFilter{From: 1000, To: 1099}},
+		{name: "open range", policy: policy, filter: &OutputFilter{From: 1000}},
+		{name: "pattern too long", policy: policy, filter: &OutputFilter{Match: "segfault at"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLandlock_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		landlock *Landlock
+		wantErr  bool
+	}{
+		{name: "nil landlock", landlock: nil},
+		{name: "read only paths", landlock: &Landlock{ReadOnly: []string{"/usr", "/etc/hostname"}}},
+		{name: "read write paths", landlock: &Landlock{ReadWrite: []string{"/tmp"}}},
+		{name: "no paths", landlock: &Landlock{}, wantErr: true},
+		{name: "relative path", landlock: &Landlock{ReadOnly: []string{"usr"}}, wantErr: true},
+		{name: "unclean path", landlock: &Landlock{ReadWrite: []string{"/tmp/"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.landlock.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilities_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		caps    *Capabilities
+		wantErr bool
+	}{
+		{name: "nil capabilities", caps: nil},
+		{name: "drop everything", caps: &Capabilities{}},
+		{name: "prefixed names", caps: &Capabilities{Ambient: []string{"CAP_NET_ADMIN"}, Bounding: []string{"CAP_NET_RAW"}}},
+		{name: "short names", caps: &Capabilities{Bounding: []string{"net_bind_service", "checkpoint_restore"}}},
+		{name: "unknown capability", caps: &Capabilities{Ambient: []string{"CAP_EVERYTHING"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.caps.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilityNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   int
+		wantOK bool
+	}{
+		{name: "CAP_CHOWN", want: 0, wantOK: true},
+		{name: "CAP_NET_ADMIN", want: 12, wantOK: true},
+		{name: "sys_admin", want: 21, wantOK: true},
+		{name: "cap_checkpoint_restore", want: 40, wantOK: true},
+		{name: "net-admin", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CapabilityNumber(tt.name)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("CapabilityNumber(%q) = %d, %v, want %d, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIOPriority_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		priority  *IOPriority
+		wantValue int
+		wantErr   bool
+	}{
+		{name: "nil priority", priority: nil},
+		{name: "idle", priority: &IOPriority{Class: "idle"}, wantValue: 3 << 13},
+		{name: "best effort lowest", priority: &IOPriority{Class: "best-effort", Level: 7}, wantValue: 2<<13 | 7},
+		{name: "realtime highest", priority: &IOPriority{Class: "realtime", Level: 0}, wantValue: 1 << 13},
+		{name: "unknown class", priority: &IOPriority{Class: "low"}, wantErr: true},
+		{name: "level too high", priority: &IOPriority{Class: "best-effort", Level: 8}, wantErr: true},
+		{name: "negative level", priority: &IOPriority{Class: "best-effort", Level: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.priority.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && tt.priority != nil && tt.priority.Value() != tt.wantValue {
+				t.Errorf("Value() = %#x, want %#x", tt.priority.Value(), tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestValidateNice(t *testing.T) {
+	nice := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		nice    *int
+		wantErr bool
+	}{
+		{name: "unset", nice: nil},
+		{name: "lowest priority", nice: nice(19)},
+		{name: "highest priority", nice: nice(-20)},
+		{name: "too low", nice: nice(20), wantErr: true},
+		{name: "too high", nice: nice(-21), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNice(tt.nice)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputParser_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  *OutputParser
+		wantErr bool
+	}{
+		{name: "nil parser", parser: nil},
+		{name: "json", parser: &OutputParser{Type: "json"}},
+		{name: "table with columns", parser: &OutputParser{Type: "table", Columns: []string{"filesystem", "size"}}},
+		{name: "regex with named group", parser: &OutputParser{Type: "regex", Pattern: `load average: (?P<load1>[\d.]+)`}},
+		{name: "regex without named group", parser: &OutputParser{Type: "regex", Pattern: `(\d+)`}, wantErr: true},
+		{name: "invalid regex", parser: &OutputParser{Type: "regex", Pattern: `(?P<x>`}, wantErr: true},
+		{name: "pattern on lines", parser: &OutputParser{Type: "lines", Pattern: `(?P<x>.)`}, wantErr: true},
+		{name: "columns on json", parser: &OutputParser{Type: "json", Columns: []string{"a"}}, wantErr: true},
+		{name: "unknown type", parser: &OutputParser{Type: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parser.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommand_ValidateSuccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		command Command
+		wantErr bool
+	}{
+		{name: "no criteria", command: Command{}},
+		{name: "exit codes and matches", command: Command{SuccessExitCodes: []int{0, 3}, SuccessStdoutMatch: `inactive`, SuccessStderrMatch: `^$`}},
+		{name: "exit code out of range", command: Command{SuccessExitCodes: []int{256}}, wantErr: true},
+		{name: "invalid stdout match", command: Command{SuccessStdoutMatch: `(`}, wantErr: true},
+		{name: "invalid stderr match", command: Command{SuccessStderrMatch: `[`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.command.ValidateSuccess()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSuccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommand_Succeeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  Command
+		exitCode int
+		stdout   string
+		stderr   string
+		want     bool
+	}{
+		{name: "default exit code 0", command: Command{}, exitCode: 0, want: true},
+		{name: "default non-zero exit", command: Command{}, exitCode: 1, want: false},
+		{name: "listed exit code", command: Command{SuccessExitCodes: []int{0, 1}}, exitCode: 1, want: true},
+		{name: "exit 0 not listed", command: Command{SuccessExitCodes: []int{3}}, exitCode: 0, want: false},
+		{name: "stdout matches", command: Command{SuccessExitCodes: []int{3}, SuccessStdoutMatch: `Active: inactive`}, exitCode: 3, stdout: "   Active: inactive (dead)\n", want: true},
+		{name: "stdout does not match", command: Command{SuccessStdoutMatch: `^OK`}, exitCode: 0, stdout: "FAIL\n", want: false},
+		{name: "stderr must be empty", command: Command{SuccessStderrMatch: `^$`}, exitCode: 0, stderr: "warning\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.command.Succeeded(tt.exitCode, []byte(tt.stdout), []byte(tt.stderr))
+			if got != tt.want {
+				t.Errorf("Succeeded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPResponse_SetOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		stdout       []byte
+		stderr       []byte
+		lines        []OutputLine
+		wantStdout   string
+		wantStderr   string
+		wantLines    []string
+		wantEncoding string
+	}{
+		{
+			name:       "text output keeps whitespace",
+			stdout:     []byte("  line 1\nline 2\n\n"),
+			stderr:     []byte("warning\n"),
+			wantStdout: "  line 1\nline 2\n\n",
+			wantStderr: "warning\n",
+		},
+		{
+			name:       "empty output",
+			stdout:     nil,
+			stderr:     nil,
+			wantStdout: "",
+			wantStderr: "",
+		},
+		{
+			name:         "binary stdout",
+			stdout:       []byte{0xff, 0xfe, 'o', 'k'},
+			stderr:       []byte("text"),
+			wantStdout:   "//5vaw==",
+			wantStderr:   "dGV4dA==",
+			wantEncoding: "base64",
+		},
+		{
+			name:         "invalid UTF-8 in stderr",
+			stdout:       []byte("ok"),
+			stderr:       []byte{0xc3},
+			wantStdout:   "b2s=",
+			wantStderr:   "ww==",
+			wantEncoding: "base64",
+		},
+		{
+			name:       "combined lines",
+			stdout:     []byte("out\n"),
+			stderr:     []byte("err\n"),
+			lines:      []OutputLine{{Stream: "stdout", Line: "out"}, {Stream: "stderr", OffsetNs: 5, Line: "err"}},
+			wantStdout: "out\n",
+			wantStderr: "err\n",
+			wantLines:  []string{"out", "err"},
+		},
+		{
+			name:         "binary line encodes everything",
+			stdout:       []byte("ok"),
+			stderr:       nil,
+			lines:        []OutputLine{{Stream: "stdout", Line: "ok"}, {Stream: "stderr", Line: "\xff"}},
+			wantStdout:   "b2s=",
+			wantStderr:   "",
+			wantLines:    []string{"b2s=", "/w=="},
+			wantEncoding: "base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp HTTPResponse
+			resp.SetOutput(tt.stdout, tt.stderr, tt.lines)
+
+			if resp.Stdout != tt.wantStdout {
+				t.Errorf("Stdout = %q, want %q", resp.Stdout, tt.wantStdout)
+			}
+			if resp.Stderr != tt.wantStderr {
+				t.Errorf("Stderr = %q, want %q", resp.Stderr, tt.wantStderr)
+			}
+			if resp.Encoding != tt.wantEncoding {
+				t.Errorf("Encoding = %q, want %q", resp.Encoding, tt.wantEncoding)
+			}
+			if len(resp.Output) != len(tt.wantLines) {
+				t.Fatalf("Output has %d lines, want %d", len(resp.Output), len(tt.wantLines))
+			}
+			for i, want := range tt.wantLines {
+				if resp.Output[i].Line != want {
+					t.Errorf("Output[%d].Line = %q, want %q", i, resp.Output[i].Line, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineRequest_Validate(t *testing.T) {
+	stages := func(n int) []PipelineStage {
+		s := make([]PipelineStage, n)
+		for i := range s {
+			s[i] = PipelineStage{Command: "cat"}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		request *PipelineRequest
+		wantErr bool
+	}{
+		{name: "two stages", request: &PipelineRequest{Stages: []PipelineStage{{Command: "ps", Args: []string{"aux"}}, {Command: "grep", Args: []string{"nginx"}}}, Timeout: 30}},
+		{name: "maximum stages", request: &PipelineRequest{Stages: stages(MaxPipelineStages), Timeout: 30}},
+		{name: "no stages", request: &PipelineRequest{Timeout: 30}, wantErr: true},
+		{name: "too many stages", request: &PipelineRequest{Stages: stages(MaxPipelineStages + 1), Timeout: 30}, wantErr: true},
+		{name: "empty command", request: &PipelineRequest{Stages: []PipelineStage{{Command: "ps"}, {Command: ""}}, Timeout: 30}, wantErr: true},
+		{name: "timeout too long", request: &PipelineRequest{Stages: stages(2), Timeout: 301}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPipelineResponse_SetOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		stdout       []byte
+		stderr       [][]byte
+		wantStdout   string
+		wantStderr   []string
+		wantEncoding string
+	}{
+		{
+			name:       "text output",
+			stdout:     []byte("nginx\n"),
+			stderr:     [][]byte{nil, []byte("warning\n")},
+			wantStdout: "nginx\n",
+			wantStderr: []string{"", "warning\n"},
+		},
+		{
+			name:         "binary stderr encodes everything",
+			stdout:       []byte("ok"),
+			stderr:       [][]byte{{0xff}, nil},
+			wantStdout:   "b2s=",
+			wantStderr:   []string{"/w==", ""},
+			wantEncoding: "base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := PipelineResponse{Stages: make([]StageResponse, len(tt.stderr))}
+			resp.SetOutput(tt.stdout, tt.stderr)
+
+			if resp.Stdout != tt.wantStdout {
+				t.Errorf("Stdout = %q, want %q", resp.Stdout, tt.wantStdout)
+			}
+			for i, want := range tt.wantStderr {
+				if resp.Stages[i].Stderr != want {
+					t.Errorf("Stages[%d].Stderr = %q, want %q", i, resp.Stages[i].Stderr, want)
+				}
+			}
+			if resp.Encoding != tt.wantEncoding {
+				t.Errorf("Encoding = %q, want %q", resp.Encoding, tt.wantEncoding)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+// Plan describes exactly what a request will run: the command, its
+// arguments and the settings the daemon will apply. A plan is returned
+// together with a signed token that can be applied once before it expires.
+type Plan struct {
+	ID         string          `json:"id"`
+	Command    string          `json:"command"`
+	Args       []string        `json:"args"`
+	Timeout    int             `json:"timeout"`
+	OutputMode string          `json:"output_mode,omitempty"`
+	Filter     *OutputFilter   `json:"filter,omitempty"`
+	Limits     *ResourceLimits `json:"limits,omitempty"`
+	Cgroup     *CgroupLimits   `json:"cgroup,omitempty"`
+	Nice       *int            `json:"nice,omitempty"`
+	IONice     *IOPriority     `json:"ionice,omitempty"`
+	Requester  string          `json:"requester,omitempty"`
+	Created    string          `json:"created"`
+	Expires    string          `json:"expires"`
+}
+
+// ApplyRequest is the body of POST /apply
+type ApplyRequest struct {
+	Token string `json:"token"`
+}
+
+// PlanResponse represents the API response for POST /plan
+type PlanResponse struct {
+	Success bool   `json:"success"`
+	Token   string `json:"token,omitempty"`
+	Plan    *Plan  `json:"plan,omitempty"`
+	Error   string `json:"error,omitempty"`
+
+	ErrorCode   string `json:"error_code,omitempty"`
+	NextAllowed string `json:"next_allowed,omitempty"`
+}
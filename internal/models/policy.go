@@ -0,0 +1,473 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zinrai/sevalet/internal/redact"
+	"github.com/zinrai/sevalet/internal/seccomp"
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize is a size in bytes that accepts K, M, G and T suffixes in YAML
+type ByteSize uint64
+
+var byteSizePattern = regexp.MustCompile(`^(\d+)\s*([KMGT]?)(I?B?)$`)
+
+// ParseByteSize parses a size such as "4096", "512K", "64MiB" or "1G".
+// Suffixes are binary multiples of 1024.
+func ParseByteSize(s string) (ByteSize, error) {
+	m := byteSizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil || (m[2] == "" && strings.HasPrefix(m[3], "I")) {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	n, err := strconv.ParseUint(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	shift := strings.Index("KMGT", m[2])*10 + 10
+	if m[2] == "" {
+		shift = 0
+	}
+	if shift > 0 && n > (^uint64(0))>>shift {
+		return 0, fmt.Errorf("size out of range: %q", s)
+	}
+
+	return ByteSize(n << shift), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// ResourceLimits defines the rlimits applied to a command before exec.
+// A zero value leaves the corresponding limit inherited from the daemon.
+type ResourceLimits struct {
+	CPUSeconds   uint64   `yaml:"cpu_seconds" json:"cpu_seconds,omitempty"`
+	AddressSpace ByteSize `yaml:"address_space" json:"address_space,omitempty"`
+	OpenFiles    uint64   `yaml:"open_files" json:"open_files,omitempty"`
+	Processes    uint64   `yaml:"processes" json:"processes,omitempty"`
+	FileSize     ByteSize `yaml:"file_size" json:"file_size,omitempty"`
+}
+
+// Validate checks that the limits leave the command enough room to start
+func (l *ResourceLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+
+	// The dynamic loader alone maps several megabytes
+	if l.AddressSpace != 0 && l.AddressSpace < 16<<20 {
+		return fmt.Errorf("address_space must be at least 16M")
+	}
+
+	// stdin, stdout and stderr are always open
+	if l.OpenFiles != 0 && l.OpenFiles < 4 {
+		return fmt.Errorf("open_files must be at least 4")
+	}
+
+	return nil
+}
+
+// CgroupLimits defines the cgroup v2 controls applied to a command's
+// execution cgroup. A zero value leaves the controller unlimited.
+type CgroupLimits struct {
+	MemoryMax ByteSize `yaml:"memory_max" json:"memory_max,omitempty"`
+	CPUMax    string   `yaml:"cpu_max" json:"cpu_max,omitempty"` // "$MAX $PERIOD" as in cpu.max
+	PidsMax   uint64   `yaml:"pids_max" json:"pids_max,omitempty"`
+}
+
+// Controllers returns the cgroup controllers the limits need
+func (l *CgroupLimits) Controllers() []string {
+	if l == nil {
+		return nil
+	}
+
+	var controllers []string
+	if l.MemoryMax != 0 {
+		controllers = append(controllers, "memory")
+	}
+	if l.CPUMax != "" {
+		controllers = append(controllers, "cpu")
+	}
+	if l.PidsMax != 0 {
+		controllers = append(controllers, "pids")
+	}
+	return controllers
+}
+
+var cpuMaxPattern = regexp.MustCompile(`^(max|[1-9]\d*)( [1-9]\d*)?$`)
+
+// Validate checks the cgroup limits against the kernel's formats
+func (l *CgroupLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+
+	if l.MemoryMax != 0 && l.MemoryMax < 1<<20 {
+		return fmt.Errorf("memory_max must be at least 1M")
+	}
+
+	if l.CPUMax != "" && !cpuMaxPattern.MatchString(l.CPUMax) {
+		return fmt.Errorf("cpu_max must be \"$MAX $PERIOD\", e.g. \"50000 100000\"")
+	}
+
+	return nil
+}
+
+// Sandbox runs a command in new mount, network and IPC namespaces with the
+// whole filesystem remounted read-only. Binds and tmpfs mounts are set up
+// before the remount; writable ones are made writable again afterwards.
+type Sandbox struct {
+	Binds []BindMount `yaml:"binds" json:"binds,omitempty"`
+	Tmpfs []string    `yaml:"tmpfs" json:"tmpfs,omitempty"`
+}
+
+// BindMount bind-mounts Source onto Target inside the sandbox
+type BindMount struct {
+	Source   string `yaml:"source" json:"source"`
+	Target   string `yaml:"target" json:"target"`
+	Writable bool   `yaml:"writable" json:"writable,omitempty"`
+}
+
+// Validate checks that all sandbox paths are absolute and clean
+func (s *Sandbox) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	for _, b := range s.Binds {
+		if err := validateSandboxPath(b.Source); err != nil {
+			return fmt.Errorf("bind source: %w", err)
+		}
+		target := b.Target
+		if target == "" {
+			target = b.Source
+		}
+		if err := validateSandboxPath(target); err != nil {
+			return fmt.Errorf("bind target: %w", err)
+		}
+	}
+
+	for _, path := range s.Tmpfs {
+		if err := validateSandboxPath(path); err != nil {
+			return fmt.Errorf("tmpfs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateSandboxPath(path string) error {
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+		return fmt.Errorf("path must be absolute and clean: %q", path)
+	}
+	if path == "/" {
+		return fmt.Errorf("path must not be the root directory")
+	}
+	return nil
+}
+
+// Seccomp restricts the system calls available to a command. Exactly one of
+// Allow and Deny must be set. Entries are system call names or groups such
+// as "@network"; denied system calls fail with EPERM.
+type Seccomp struct {
+	Allow []string `yaml:"allow" json:"allow,omitempty"`
+	Deny  []string `yaml:"deny" json:"deny,omitempty"`
+}
+
+// Validate checks that the filter is usable on this architecture
+func (s *Seccomp) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	if (len(s.Allow) == 0) == (len(s.Deny) == 0) {
+		return fmt.Errorf("exactly one of allow or deny must be set")
+	}
+
+	for _, name := range s.Deny {
+		if name == "execve" {
+			return fmt.Errorf("execve can't be denied as it is needed to start the command")
+		}
+	}
+
+	_, err := seccomp.Resolve(append(s.Allow, s.Deny...))
+	return err
+}
+
+// Redaction masks secrets in command output using built-in detectors and
+// custom rules, applied in that order
+type Redaction struct {
+	Detectors []string      `yaml:"detectors" json:"detectors,omitempty"`
+	Rules     []redact.Rule `yaml:"rules" json:"rules,omitempty"`
+}
+
+// Validate checks detector names and compiles the rules
+func (r *Redaction) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	_, err := redact.New(r.Detectors, r.Rules)
+	return err
+}
+
+// ErrFilterNotAllowed is wrapped by errors for filters a policy rejects
+var ErrFilterNotAllowed = errors.New("output filter not allowed")
+
+// FilterPolicy permits requests to filter a command's output. Commands
+// without one don't accept filters.
+type FilterPolicy struct {
+	// Allow lists the permitted filter kinds; all are permitted if empty
+	Allow []string `yaml:"allow" json:"allow,omitempty"`
+	// MaxLines bounds head, tail and line ranges and caps the number of
+	// lines a filter returns, unlimited if zero
+	MaxLines int `yaml:"max_lines" json:"max_lines,omitempty"`
+	// MaxPatternLength bounds match strings and regular expressions,
+	// 256 if zero
+	MaxPatternLength int `yaml:"max_pattern_length" json:"max_pattern_length,omitempty"`
+}
+
+// defaultMaxPatternLength applies when MaxPatternLength is zero
+const defaultMaxPatternLength = 256
+
+// Validate checks filter kinds and bounds
+func (p *FilterPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	for _, kind := range p.Allow {
+		switch kind {
+		case FilterHead, FilterTail, FilterLines, FilterMatch, FilterRegex:
+		default:
+			return fmt.Errorf("unknown filter kind: %s", kind)
+		}
+	}
+
+	if p.MaxLines < 0 || p.MaxPatternLength < 0 {
+		return fmt.Errorf("max_lines and max_pattern_length must not be negative")
+	}
+
+	return nil
+}
+
+// Check reports whether a request's filter is permitted. A nil filter
+// always is.
+func (p *FilterPolicy) Check(f *OutputFilter) error {
+	if f == nil {
+		return nil
+	}
+	if p == nil {
+		return fmt.Errorf("%w for this command", ErrFilterNotAllowed)
+	}
+
+	for _, kind := range f.Kinds() {
+		if len(p.Allow) > 0 && !slices.Contains(p.Allow, kind) {
+			return fmt.Errorf("%w: %s", ErrFilterNotAllowed, kind)
+		}
+	}
+
+	if p.MaxLines > 0 {
+		if f.Head > p.MaxLines || f.Tail > p.MaxLines {
+			return fmt.Errorf("%w: more than %d lines", ErrFilterNotAllowed, p.MaxLines)
+		}
+		if f.From > 0 && f.To-f.From+1 > p.MaxLines || f.From == 0 && f.To > p.MaxLines {
+			return fmt.Errorf("%w: more than %d lines", ErrFilterNotAllowed, p.MaxLines)
+		}
+	}
+
+	maxPattern := p.MaxPatternLength
+	if maxPattern == 0 {
+		maxPattern = defaultMaxPatternLength
+	}
+	if len(f.Match) > maxPattern || len(f.Regex) > maxPattern {
+		return fmt.Errorf("%w: pattern longer than %d bytes", ErrFilterNotAllowed, maxPattern)
+	}
+
+	return nil
+}
+
+// Landlock restricts a command's filesystem access to the listed paths and
+// everything beneath them. Files outside all of them can't be accessed, so
+// the command's binary and libraries must be covered as well.
+type Landlock struct {
+	ReadOnly  []string `yaml:"read_only" json:"read_only,omitempty"`
+	ReadWrite []string `yaml:"read_write" json:"read_write,omitempty"`
+}
+
+// Validate checks that the rules are absolute paths
+func (l *Landlock) Validate() error {
+	if l == nil {
+		return nil
+	}
+
+	if len(l.ReadOnly) == 0 && len(l.ReadWrite) == 0 {
+		return fmt.Errorf("at least one of read_only or read_write must be set")
+	}
+
+	for _, path := range append(l.ReadOnly, l.ReadWrite...) {
+		if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+			return fmt.Errorf("path must be absolute and clean: %q", path)
+		}
+	}
+
+	return nil
+}
+
+// capabilityNames lists the Linux capabilities in the order of their numbers
+var capabilityNames = []string{
+	"chown", "dac_override", "dac_read_search", "fowner", "fsetid", "kill",
+	"setgid", "setuid", "setpcap", "linux_immutable", "net_bind_service",
+	"net_broadcast", "net_admin", "net_raw", "ipc_lock", "ipc_owner",
+	"sys_module", "sys_rawio", "sys_chroot", "sys_ptrace", "sys_pacct",
+	"sys_admin", "sys_boot", "sys_nice", "sys_resource", "sys_time",
+	"sys_tty_config", "mknod", "lease", "audit_write", "audit_control",
+	"setfcap", "mac_override", "mac_admin", "syslog", "wake_alarm",
+	"block_suspend", "audit_read", "perfmon", "bpf", "checkpoint_restore",
+}
+
+// CapabilityNumber returns the number of a capability given as e.g.
+// "CAP_NET_ADMIN" or "net_admin"
+func CapabilityNumber(name string) (int, bool) {
+	name = strings.TrimPrefix(strings.ToLower(name), "cap_")
+	for i, n := range capabilityNames {
+		if n == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Capabilities restricts the capabilities a command runs with. Everything
+// not listed in either set is dropped from the bounding set. Ambient
+// capabilities are additionally raised so that a command run by a non-root
+// daemon keeps them across exec; the daemon must hold them itself.
+type Capabilities struct {
+	Ambient  []string `yaml:"ambient" json:"ambient,omitempty"`
+	Bounding []string `yaml:"bounding" json:"bounding,omitempty"`
+}
+
+// Validate checks that all capability names are known
+func (c *Capabilities) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, name := range append(c.Ambient, c.Bounding...) {
+		if _, ok := CapabilityNumber(name); !ok {
+			return fmt.Errorf("unknown capability: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// IOPriority selects the I/O scheduling class and priority level of a
+// command, as with ionice(1)
+type IOPriority struct {
+	Class string `yaml:"class" json:"class"` // realtime, best-effort or idle
+	Level int    `yaml:"level" json:"level"` // 0 (highest) to 7, unused for idle
+}
+
+// ioPriorityClasses maps class names to IOPRIO_CLASS_* values
+var ioPriorityClasses = map[string]int{
+	"realtime":    1,
+	"best-effort": 2,
+	"idle":        3,
+}
+
+// Value returns the ioprio value for ioprio_set(2)
+func (p *IOPriority) Value() int {
+	return ioPriorityClasses[p.Class]<<13 | p.Level
+}
+
+// Validate checks the class name and level range
+func (p *IOPriority) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if _, ok := ioPriorityClasses[p.Class]; !ok {
+		return fmt.Errorf("class must be realtime, best-effort or idle")
+	}
+
+	if p.Level < 0 || p.Level > 7 {
+		return fmt.Errorf("level must be between 0 and 7")
+	}
+
+	return nil
+}
+
+// ValidateNice checks that a nice value is within the kernel's range
+func ValidateNice(nice *int) error {
+	if nice != nil && (*nice < -20 || *nice > 19) {
+		return fmt.Errorf("nice must be between -20 and 19")
+	}
+	return nil
+}
+
+// Output parser types
+const (
+	OutputParserJSON  = "json"  // stdout is a JSON document
+	OutputParserLines = "lines" // one string per non-empty line
+	OutputParserTable = "table" // whitespace separated columns with a header
+	OutputParserRegex = "regex" // named capture groups of every match
+)
+
+// OutputParser turns a command's stdout into structured data
+type OutputParser struct {
+	Type string `yaml:"type" json:"type"`
+	// Pattern is the regular expression for the regex parser
+	Pattern string `yaml:"pattern" json:"pattern,omitempty"`
+	// Columns replaces the header names of the table parser
+	Columns []string `yaml:"columns" json:"columns,omitempty"`
+}
+
+// Validate checks the parser type and its settings
+func (p *OutputParser) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	switch p.Type {
+	case OutputParserJSON, OutputParserLines, OutputParserTable:
+		if p.Pattern != "" {
+			return fmt.Errorf("pattern is only used by the regex parser")
+		}
+	case OutputParserRegex:
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		named := false
+		for _, name := range re.SubexpNames() {
+			named = named || name != ""
+		}
+		if !named {
+			return fmt.Errorf("pattern must have at least one named group")
+		}
+	default:
+		return fmt.Errorf("type must be json, lines, table or regex")
+	}
+
+	if len(p.Columns) > 0 && p.Type != OutputParserTable {
+		return fmt.Errorf("columns are only used by the table parser")
+	}
+
+	return nil
+}
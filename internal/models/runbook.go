@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// Runbook is a named sequence of allowlisted commands run by the daemon
+type Runbook struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// Timeout bounds the whole runbook in seconds, max_execution_time if
+	// zero. Compensation steps are not bound by it.
+	Timeout int           `yaml:"timeout" json:"timeout,omitempty"`
+	Steps   []RunbookStep `yaml:"steps" json:"steps"`
+}
+
+// RunbookStep runs one command. A step fails if the command doesn't meet
+// its success criteria; the runbook then runs the step's OnFailure steps
+// and stops, unless ContinueOnFailure is set.
+type RunbookStep struct {
+	Name    string   `yaml:"name" json:"name"`
+	Command string   `yaml:"command" json:"command"`
+	Args    []string `yaml:"args" json:"args"`
+	// Timeout in seconds, default_timeout if zero
+	Timeout           int            `yaml:"timeout" json:"timeout,omitempty"`
+	When              *StepCondition `yaml:"when" json:"when,omitempty"`
+	ContinueOnFailure bool           `yaml:"continue_on_failure" json:"continue_on_failure,omitempty"`
+	OnFailure         []RunbookStep  `yaml:"on_failure" json:"on_failure,omitempty"`
+}
+
+// StepCondition runs a step only if an earlier step exited with one of the
+// listed codes. The step is skipped otherwise, and also if the earlier
+// step was skipped or could not be run.
+type StepCondition struct {
+	// Step names the earlier step, the previous one if empty
+	Step      string `yaml:"step" json:"step,omitempty"`
+	ExitCodes []int  `yaml:"exit_codes" json:"exit_codes"`
+}
+
+// Runbook step statuses
+const (
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"
+)
+
+// Validate checks the structure of the runbook. Whether the steps' commands
+// are allowed is checked against the command list by the configuration.
+func (r *Runbook) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("runbook name is not specified")
+	}
+	if r.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("runbook has no steps")
+	}
+
+	var names []string
+	for i, step := range r.Steps {
+		if err := step.validate(false); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if slices.Contains(names, step.Name) {
+			return fmt.Errorf("step %d: duplicate step name %s", i+1, step.Name)
+		}
+
+		if step.When != nil {
+			if i == 0 && step.When.Step == "" {
+				return fmt.Errorf("step %d: condition of the first step must name a step", i+1)
+			}
+			if step.When.Step != "" && !slices.Contains(names, step.When.Step) {
+				return fmt.Errorf("step %d: condition refers to %s, which is not an earlier step", i+1, step.When.Step)
+			}
+		}
+
+		names = append(names, step.Name)
+	}
+
+	return nil
+}
+
+// validate checks a single step. Compensation steps can't have conditions
+// or compensation steps of their own.
+func (s *RunbookStep) validate(compensation bool) error {
+	if s.Name == "" {
+		return fmt.Errorf("step name is not specified")
+	}
+	if s.Command == "" {
+		return fmt.Errorf("command is not specified")
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if s.When != nil && len(s.When.ExitCodes) == 0 {
+		return fmt.Errorf("condition has no exit codes")
+	}
+
+	if compensation {
+		if s.When != nil || s.ContinueOnFailure || len(s.OnFailure) > 0 {
+			return fmt.Errorf("on_failure steps can't set when, continue_on_failure or on_failure")
+		}
+		return nil
+	}
+
+	for i, step := range s.OnFailure {
+		if err := step.validate(true); err != nil {
+			return fmt.Errorf("on_failure step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// FindRunbook searches for a runbook by name
+func FindRunbook(runbooks []Runbook, name string) *Runbook {
+	for i := range runbooks {
+		if runbooks[i].Name == name {
+			return &runbooks[i]
+		}
+	}
+	return nil
+}
+
+// RunbookResponse represents the API response for a runbook
+type RunbookResponse struct {
+	Success       bool           `json:"success"`
+	Succeeded     bool           `json:"succeeded"`
+	Runbook       string         `json:"runbook,omitempty"`
+	Steps         []StepResponse `json:"steps,omitempty"`
+	ExecutionTime string         `json:"execution_time,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	ErrorCode     string         `json:"error_code,omitempty"`
+	NextAllowed   string         `json:"next_allowed,omitempty"`
+}
+
+// StepResponse reports the result of a runbook step
+type StepResponse struct {
+	Name          string   `json:"name"`
+	Command       string   `json:"command"`
+	Args          []string `json:"args,omitempty"`
+	Status        string   `json:"status"`
+	Compensates   string   `json:"compensates,omitempty"` // Step whose failure ran this one
+	ExitCode      int      `json:"exit_code"`
+	Stdout        string   `json:"stdout,omitempty"`
+	Stderr        string   `json:"stderr,omitempty"`
+	Encoding      string   `json:"encoding,omitempty"`
+	ExecutionTime string   `json:"execution_time,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// SetOutput stores the step's output, base64 encoded as with
+// HTTPResponse.SetOutput if it is not valid UTF-8
+func (r *StepResponse) SetOutput(stdout, stderr []byte) {
+	if utf8.Valid(stdout) && utf8.Valid(stderr) {
+		r.Stdout = string(stdout)
+		r.Stderr = string(stderr)
+		r.Encoding = ""
+		return
+	}
+
+	r.Stdout = base64.StdEncoding.EncodeToString(stdout)
+	r.Stderr = base64.StdEncoding.EncodeToString(stderr)
+	r.Encoding = "base64"
+}
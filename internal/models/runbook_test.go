@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRunbook_Validate(t *testing.T) {
+	step := func(name string) RunbookStep {
+		return RunbookStep{Name: name, Command: "systemctl", Args: []string{"status", "nginx"}}
+	}
+	withWhen := func(s RunbookStep, ref string, codes ...int) RunbookStep {
+		s.When = &StepCondition{Step: ref, ExitCodes: codes}
+		return s
+	}
+	withOnFailure := func(s RunbookStep, steps ...RunbookStep) RunbookStep {
+		s.OnFailure = steps
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		runbook Runbook
+		wantErr bool
+	}{
+		{name: "single step", runbook: Runbook{Name: "check", Steps: []RunbookStep{step("status")}}},
+		{name: "condition on previous step", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{step("stop"), withWhen(step("start"), "", 0)}}},
+		{name: "condition on named step", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{step("verify"), step("stop"), withWhen(step("start"), "verify", 0, 3)}}},
+		{name: "compensation", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{withOnFailure(step("stop"), step("start"))}}},
+		{name: "missing name", runbook: Runbook{Steps: []RunbookStep{step("status")}}, wantErr: true},
+		{name: "no steps", runbook: Runbook{Name: "empty"}, wantErr: true},
+		{name: "negative timeout", runbook: Runbook{Name: "deploy", Timeout: -1, Steps: []RunbookStep{step("status")}}, wantErr: true},
+		{name: "step without command", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{{Name: "status"}}}, wantErr: true},
+		{name: "duplicate step names", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{step("stop"), step("stop")}}, wantErr: true},
+		{name: "condition on first step", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{withWhen(step("stop"), "", 0)}}, wantErr: true},
+		{name: "condition on later step", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{withWhen(step("stop"), "start", 0), step("start")}}, wantErr: true},
+		{name: "condition without exit codes", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{step("stop"), withWhen(step("start"), "")}}, wantErr: true},
+		{name: "nested compensation", runbook: Runbook{Name: "deploy", Steps: []RunbookStep{withOnFailure(step("stop"), withOnFailure(step("start"), step("status")))}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.runbook.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
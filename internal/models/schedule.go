@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/zinrai/sevalet/internal/cron"
+)
+
+// Schedule runs an allowlisted command or a runbook periodically in the daemon
+type Schedule struct {
+	Name string `yaml:"name" json:"name"`
+	// Cron is a five-field cron expression in the daemon's local time
+	Cron    string   `yaml:"cron" json:"cron"`
+	Command string   `yaml:"command" json:"command,omitempty"`
+	Args    []string `yaml:"args" json:"args,omitempty"`
+	Runbook string   `yaml:"runbook" json:"runbook,omitempty"`
+	// Timeout in seconds for a command, default_timeout if zero. Runbooks
+	// use their own timeout.
+	Timeout int `yaml:"timeout" json:"timeout,omitempty"`
+	// Jitter delays each run by a random number of seconds up to this
+	Jitter  int    `yaml:"jitter" json:"jitter,omitempty"`
+	Overlap string `yaml:"overlap" json:"overlap,omitempty"`
+	// CallbackURL receives a completion webhook after each run, sent by
+	// the API if its webhooks allow the URL
+	CallbackURL string `yaml:"callback_url" json:"callback_url,omitempty"`
+}
+
+// Overlap policies decide what happens when a run is due while the
+// previous one is still running
+const (
+	OverlapSkip  = "skip"  // Drop the run (default)
+	OverlapQueue = "queue" // Run once the previous run finishes, keeping at most one pending
+)
+
+// Validate checks the structure of the schedule. Whether its command is
+// allowed and its runbook exists is checked by the configuration.
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("schedule name is not specified")
+	}
+	if _, err := cron.Parse(s.Cron); err != nil {
+		return fmt.Errorf("invalid cron: %w", err)
+	}
+	if (s.Command == "") == (s.Runbook == "") {
+		return fmt.Errorf("exactly one of command and runbook must be specified")
+	}
+	if s.Runbook != "" && (len(s.Args) > 0 || s.Timeout != 0) {
+		return fmt.Errorf("args and timeout only apply to commands")
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if s.Jitter < 0 {
+		return fmt.Errorf("jitter must not be negative")
+	}
+
+	switch s.Overlap {
+	case "", OverlapSkip, OverlapQueue:
+	default:
+		return fmt.Errorf("overlap must be %s or %s: %s", OverlapSkip, OverlapQueue, s.Overlap)
+	}
+
+	if s.CallbackURL != "" {
+		if err := validateWebhookURL(s.CallbackURL); err != nil {
+			return fmt.Errorf("invalid callback_url: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ScheduleStatus reports the state of a schedule. The last run's status
+// is StepSucceeded or StepFailed.
+type ScheduleStatus struct {
+	Schedule
+	Running      bool   `json:"running"`
+	Queued       bool   `json:"queued,omitempty"`
+	LastRun      string `json:"last_run,omitempty"`
+	LastStatus   string `json:"last_status,omitempty"`
+	LastDuration string `json:"last_duration,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+	NextRun      string `json:"next_run,omitempty"`
+	Runs         int64  `json:"runs"`
+	Skipped      int64  `json:"skipped"`
+}
+
+// SchedulesResponse represents the API response for GET /schedules
+type SchedulesResponse struct {
+	Success   bool             `json:"success"`
+	Schedules []ScheduleStatus `json:"schedules"`
+	Error     string           `json:"error,omitempty"`
+}
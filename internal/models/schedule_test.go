@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  bool
+	}{
+		{name: "command", schedule: Schedule{Name: "disk", Cron: "*/5 * * * *", Command: "df", Args: []string{"-h"}}},
+		{name: "runbook", schedule: Schedule{Name: "nightly", Cron: "@daily", Runbook: "restart-nginx"}},
+		{name: "jitter and queue", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", Jitter: 30, Overlap: OverlapQueue}},
+		{name: "missing name", schedule: Schedule{Cron: "@hourly", Command: "df"}, wantErr: true},
+		{name: "invalid cron", schedule: Schedule{Name: "disk", Cron: "61 * * * *", Command: "df"}, wantErr: true},
+		{name: "no target", schedule: Schedule{Name: "disk", Cron: "@hourly"}, wantErr: true},
+		{name: "both targets", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", Runbook: "restart-nginx"}, wantErr: true},
+		{name: "runbook with args", schedule: Schedule{Name: "nightly", Cron: "@daily", Runbook: "restart-nginx", Args: []string{"x"}}, wantErr: true},
+		{name: "negative jitter", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", Jitter: -1}, wantErr: true},
+		{name: "callback", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", CallbackURL: "https://ci.example.com/hooks/disk"}},
+		{name: "relative callback", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", CallbackURL: "/hooks/disk"}, wantErr: true},
+		{name: "unknown overlap", schedule: Schedule{Name: "disk", Cron: "@hourly", Command: "df", Overlap: "parallel"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
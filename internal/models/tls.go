@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+)
+
+// TLSConfig serves the API over TLS. The files are read again when they
+// change.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile is a PEM bundle of the CAs client certificates are
+	// verified against. Without it client certificates aren't requested.
+	ClientCAFile string `yaml:"client_ca_file"`
+	// RequireClientCert rejects connections without a valid client
+	// certificate. Otherwise they are only verified if given.
+	RequireClientCert bool `yaml:"require_client_cert"`
+}
+
+// Validate checks the TLS configuration
+func (c *TLSConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("cert_file and key_file must be specified")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return fmt.Errorf("require_client_cert needs client_ca_file")
+	}
+	return nil
+}
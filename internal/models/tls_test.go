@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestTLSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *TLSConfig
+		wantErr bool
+	}{
+		{name: "none", config: nil},
+		{name: "server only", config: &TLSConfig{CertFile: "api.crt", KeyFile: "api.key"}},
+		{
+			name:   "required client certificates",
+			config: &TLSConfig{CertFile: "api.crt", KeyFile: "api.key", ClientCAFile: "ca.pem", RequireClientCert: true},
+		},
+		{name: "no key", config: &TLSConfig{CertFile: "api.crt"}, wantErr: true},
+		{
+			name:    "required client certificates without CA",
+			config:  &TLSConfig{CertFile: "api.crt", KeyFile: "api.key", RequireClientCert: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WebhookConfig allows completion payloads to be sent to callback URLs
+type WebhookConfig struct {
+	// AllowedURLs lists the destinations callbacks may use. An entry ending
+	// in "/" also allows every URL below it.
+	AllowedURLs []string `yaml:"allowed_urls"`
+	// Secret signs the payloads, read from SecretFile if that is set
+	Secret     string `yaml:"secret"`
+	SecretFile string `yaml:"secret_file"`
+	// MaxAttempts bounds the attempts per delivery, 5 if zero
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is the delay in seconds before the first retry, 1 if
+	// zero, doubling after each attempt up to MaxBackoff, 60 if zero
+	InitialBackoff int `yaml:"initial_backoff"`
+	MaxBackoff     int `yaml:"max_backoff"`
+	// Timeout bounds each attempt in seconds, 10 if zero
+	Timeout int `yaml:"timeout"`
+}
+
+// Validate checks the webhook configuration. A nil configuration allows no
+// callbacks.
+func (c *WebhookConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.AllowedURLs) == 0 {
+		return fmt.Errorf("allowed_urls is empty")
+	}
+	for _, u := range c.AllowedURLs {
+		if err := validateWebhookURL(u); err != nil {
+			return fmt.Errorf("invalid allowed url %s: %w", u, err)
+		}
+	}
+	if (c.Secret == "") == (c.SecretFile == "") {
+		return fmt.Errorf("exactly one of secret and secret_file must be specified")
+	}
+	if c.MaxAttempts < 0 || c.InitialBackoff < 0 || c.MaxBackoff < 0 || c.Timeout < 0 {
+		return fmt.Errorf("max_attempts, initial_backoff, max_backoff and timeout must not be negative")
+	}
+
+	return nil
+}
+
+// Allows checks whether a callback URL is allowed. The scheme, host and
+// path are compared separately, and paths with "." or ".." segments, which
+// the receiver may resolve to somewhere else, are never allowed.
+func (c *WebhookConfig) Allows(callbackURL string) bool {
+	if c == nil || validateWebhookURL(callbackURL) != nil {
+		return false
+	}
+	u, _ := url.Parse(callbackURL)
+	if hasDotSegment(u.Path) {
+		return false
+	}
+
+	for _, s := range c.AllowedURLs {
+		allowed, err := url.Parse(s)
+		if err != nil || u.Scheme != allowed.Scheme || !strings.EqualFold(u.Host, allowed.Host) {
+			continue
+		}
+		if strings.HasSuffix(allowed.Path, "/") && strings.HasPrefix(u.Path, allowed.Path) {
+			return true
+		}
+		if u.Path == allowed.Path && u.RawQuery == allowed.RawQuery {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDotSegment reports whether a decoded URL path has a "." or ".."
+// segment
+func hasDotSegment(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// validateWebhookURL checks that a URL is an absolute http(s) URL without
+// credentials
+func validateWebhookURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is not specified")
+	}
+	if u.User != nil {
+		return fmt.Errorf("credentials are not allowed")
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("fragments are not allowed")
+	}
+	return nil
+}
+
+// Callback is the part of a request body that asks for a completion
+// webhook instead of waiting for the result
+type Callback struct {
+	URL string `json:"callback_url"`
+}
+
+// AcceptedResponse represents the API response for a request that runs in
+// the background and reports its result to a callback URL
+type AcceptedResponse struct {
+	Success  bool   `json:"success"`
+	Delivery string `json:"delivery"`
+}
+
+// WebhookPayload is the signed JSON body sent to a callback URL
+type WebhookPayload struct {
+	Delivery  string `json:"delivery"`
+	Event     string `json:"event"`
+	Timestamp string `json:"timestamp"`
+	Data      any    `json:"data"`
+}
+
+// CompletedRequest is the payload data for an API request: the response it
+// would have returned
+type CompletedRequest struct {
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	StatusCode int             `json:"status_code"`
+	Response   json.RawMessage `json:"response"`
+}
+
+// CompletedSchedule is the payload data for a scheduled run
+type CompletedSchedule struct {
+	Schedule string `json:"schedule"`
+	Started  string `json:"started"`
+	Duration string `json:"duration"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery records a webhook delivery and its attempts
+type Delivery struct {
+	ID       string            `json:"id"`
+	Event    string            `json:"event"`
+	URL      string            `json:"url"`
+	Status   string            `json:"status"`
+	Created  string            `json:"created"`
+	Attempts []DeliveryAttempt `json:"attempts"`
+}
+
+// DeliveryAttempt records one attempt to send a payload
+type DeliveryAttempt struct {
+	Time       string `json:"time"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Duration   string `json:"duration"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DeliveryResponse represents the API response for GET /deliveries/{id}
+type DeliveryResponse struct {
+	Success  bool      `json:"success"`
+	Delivery *Delivery `json:"delivery"`
+}
+
+// DeliveriesResponse represents the API response for GET /deliveries
+type DeliveriesResponse struct {
+	Success    bool       `json:"success"`
+	Deliveries []Delivery `json:"deliveries"`
+}
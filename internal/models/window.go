@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/window"
+)
+
+// Window is a named maintenance window that commands can be restricted to
+type Window struct {
+	Name string `yaml:"name"`
+	// Timezone of the ranges, such as "Europe/Berlin", local time if empty
+	Timezone string        `yaml:"timezone"`
+	Ranges   []WindowRange `yaml:"ranges"`
+}
+
+// WindowRange is a daily time range. A range that ends at or before its
+// start ends on the next day.
+type WindowRange struct {
+	Days  []string `yaml:"days"`  // Days the range starts on (mon to sun), every day if empty
+	Start string   `yaml:"start"` // HH:MM
+	End   string   `yaml:"end"`   // HH:MM
+}
+
+// Parse parses the window
+func (w *Window) Parse() (*window.Window, error) {
+	var ranges []window.Range
+	for i, r := range w.Ranges {
+		parsed, err := window.ParseRange(r.Days, r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("range %d: %w", i+1, err)
+		}
+		ranges = append(ranges, parsed)
+	}
+	return window.New(w.Timezone, ranges)
+}
+
+// Validate checks the window
+func (w *Window) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("window name is not specified")
+	}
+	_, err := w.Parse()
+	return err
+}
+
+// Freeze is a named change freeze during which commands that reference it
+// are rejected
+type Freeze struct {
+	Name   string `yaml:"name"`
+	Start  string `yaml:"start"` // RFC 3339
+	End    string `yaml:"end"`   // RFC 3339
+	Reason string `yaml:"reason"`
+}
+
+// Period returns the start and end of the freeze
+func (f *Freeze) Period() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, f.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, f.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %w", err)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be after start")
+	}
+	return start, end, nil
+}
+
+// Validate checks the freeze
+func (f *Freeze) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("freeze name is not specified")
+	}
+	_, _, err := f.Period()
+	return err
+}
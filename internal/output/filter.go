@@ -0,0 +1,80 @@
+package output
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// Filter returns the lines of data selected by the filter, with their line
+// terminators, and the number of selected lines dropped to keep at most
+// maxLines if it is positive. The filter is assumed to have been
+// validated.
+func Filter(f *models.OutputFilter, maxLines int, data []byte) ([]byte, int) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	selected, truncated := Select(f, maxLines, lines)
+	var out []byte
+	for _, i := range selected {
+		out = append(out, lines[i]...)
+	}
+	return out, truncated
+}
+
+// Select returns the indexes of the lines selected by the filter and the
+// number of them dropped to keep at most maxLines if it is positive. Line
+// ranges count the lines of the output, before matching. Lines may end with
+// a newline, which is ignored when matching.
+//
+// Requests for the first lines, by head or a range, keep the first
+// maxLines; others keep the last, so that the most recent matches of a log
+// are returned.
+func Select(f *models.OutputFilter, maxLines int, lines [][]byte) ([]int, int) {
+	var re *regexp.Regexp
+	if f.Regex != "" {
+		re = regexp.MustCompile(f.Regex)
+	}
+
+	from, to := 1, len(lines)
+	if f.From > 0 {
+		from = f.From
+	}
+	if f.To > 0 {
+		to = min(f.To, to)
+	}
+
+	selected := []int{}
+	for i := from - 1; i < to; i++ {
+		line := bytes.TrimSuffix(bytes.TrimSuffix(lines[i], []byte("\n")), []byte("\r"))
+		if f.Match != "" && !bytes.Contains(line, []byte(f.Match)) {
+			continue
+		}
+		if re != nil && !re.Match(line) {
+			continue
+		}
+		selected = append(selected, i)
+	}
+
+	if f.Head > 0 && len(selected) > f.Head {
+		selected = selected[:f.Head]
+	}
+	if f.Tail > 0 && len(selected) > f.Tail {
+		selected = selected[len(selected)-f.Tail:]
+	}
+
+	truncated := 0
+	if maxLines > 0 && len(selected) > maxLines {
+		truncated = len(selected) - maxLines
+		if f.Head > 0 || f.From > 0 || f.To > 0 {
+			selected = selected[:maxLines]
+		} else {
+			selected = selected[truncated:]
+		}
+	}
+
+	return selected, truncated
+}
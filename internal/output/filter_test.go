@@ -0,0 +1,46 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestFilter(t *testing.T) {
+	const log = "info start\nerror disk\ninfo running\nerror net\ninfo stop\n"
+
+	tests := []struct {
+		name          string
+		filter        *models.OutputFilter
+		maxLines      int
+		data          string
+		want          string
+		wantTruncated int
+	}{
+		{name: "head", filter: &models.OutputFilter{Head: 2}, data: log, want: "info start\nerror disk\n"},
+		{name: "tail", filter: &models.OutputFilter{Tail: 2}, data: log, want: "error net\ninfo stop\n"},
+		{name: "range", filter: &models.OutputFilter{From: 2, To: 3}, data: log, want: "error disk\ninfo running\n"},
+		{name: "open range", filter: &models.OutputFilter{From: 5}, data: log, want: "info stop\n"},
+		{name: "range past end", filter: &models.OutputFilter{From: 9}, data: log, want: ""},
+		{name: "match", filter: &models.OutputFilter{Match: "error"}, data: log, want: "error disk\nerror net\n"},
+		{name: "regex", filter: &models.OutputFilter{Regex: `^info (start|stop)$`}, data: log, want: "info start\ninfo stop\n"},
+		{name: "match then tail", filter: &models.OutputFilter{Match: "info", Tail: 1}, data: log, want: "info stop\n"},
+		{name: "range then match", filter: &models.OutputFilter{From: 2, To: 4, Match: "error"}, data: log, want: "error disk\nerror net\n"},
+		{name: "range past matches", filter: &models.OutputFilter{From: 3, Match: "error"}, data: log, want: "error net\n"},
+		{name: "max lines keeps the last matches", filter: &models.OutputFilter{Match: "info"}, maxLines: 2, data: log, want: "info running\ninfo stop\n", wantTruncated: 1},
+		{name: "max lines keeps the start of a range", filter: &models.OutputFilter{From: 2}, maxLines: 2, data: log, want: "error disk\ninfo running\n", wantTruncated: 2},
+		{name: "max lines not reached", filter: &models.OutputFilter{Match: "error"}, maxLines: 2, data: log, want: "error disk\nerror net\n"},
+		{name: "unterminated last line", filter: &models.OutputFilter{Tail: 1}, data: "a\nb", want: "b"},
+		{name: "crlf", filter: &models.OutputFilter{Regex: `b$`}, data: "a\r\nb\r\n", want: "b\r\n"},
+		{name: "empty output", filter: &models.OutputFilter{Head: 1}, data: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := Filter(tt.filter, tt.maxLines, []byte(tt.data))
+			if string(got) != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("Filter() = %q, %d, want %q, %d", got, truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package output post-processes the output of executed commands
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// Parse converts stdout into JSON according to the parser configuration.
+// The parser is assumed to have been validated.
+func Parse(parser *models.OutputParser, stdout []byte) (json.RawMessage, error) {
+	var v interface{}
+
+	switch parser.Type {
+	case models.OutputParserJSON:
+		data := bytes.TrimSpace(stdout)
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("stdout is not valid JSON")
+		}
+		return json.RawMessage(data), nil
+	case models.OutputParserLines:
+		v = parseLines(stdout)
+	case models.OutputParserTable:
+		v = parseTable(stdout, parser.Columns)
+	case models.OutputParserRegex:
+		re, err := regexp.Compile(parser.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		v = parseRegex(re, stdout)
+	default:
+		return nil, fmt.Errorf("unknown parser type: %s", parser.Type)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parsed output: %w", err)
+	}
+	return data, nil
+}
+
+// parseLines returns the non-empty lines of the output
+func parseLines(stdout []byte) []string {
+	lines := []string{}
+	for _, line := range strings.Split(string(stdout), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseTable splits whitespace separated columns into one object per row,
+// keyed by the header names or by columns if given. The first line is
+// always the header. Extra fields are joined into the last column, so that
+// trailing values containing spaces such as command lines are kept whole;
+// missing fields are left out of the row.
+func parseTable(stdout []byte, columns []string) []map[string]string {
+	rows := []map[string]string{}
+	lines := parseLines(stdout)
+	if len(lines) == 0 {
+		return rows
+	}
+
+	if len(columns) == 0 {
+		columns = strings.Fields(lines[0])
+	}
+
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		row := make(map[string]string, len(columns))
+		for i, name := range columns {
+			if i >= len(fields) {
+				break
+			}
+			if i == len(columns)-1 {
+				row[name] = strings.Join(fields[i:], " ")
+				break
+			}
+			row[name] = fields[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// parseRegex returns the named groups of every match of re
+func parseRegex(re *regexp.Regexp, stdout []byte) []map[string]string {
+	matches := []map[string]string{}
+	names := re.SubexpNames()
+
+	for _, m := range re.FindAllSubmatchIndex(stdout, -1) {
+		match := make(map[string]string)
+		for i, name := range names {
+			if name == "" || m[2*i] < 0 {
+				continue
+			}
+			match[name] = string(stdout[m[2*i]:m[2*i+1]])
+		}
+		matches = append(matches, match)
+	}
+
+	return matches
+}
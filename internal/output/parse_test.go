@@ -0,0 +1,84 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  *models.OutputParser
+		stdout  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json object",
+			parser: &models.OutputParser{Type: "json"},
+			stdout: "{\"status\": \"ok\"}\n",
+			want:   `{"status": "ok"}`,
+		},
+		{
+			name:    "invalid json",
+			parser:  &models.OutputParser{Type: "json"},
+			stdout:  "status: ok\n",
+			wantErr: true,
+		},
+		{
+			name:   "lines skip blank lines",
+			parser: &models.OutputParser{Type: "lines"},
+			stdout: "nginx\r\n\n  \nsshd\n",
+			want:   `["nginx","sshd"]`,
+		},
+		{
+			name:   "empty lines",
+			parser: &models.OutputParser{Type: "lines"},
+			stdout: "",
+			want:   `[]`,
+		},
+		{
+			name:   "table with header",
+			parser: &models.OutputParser{Type: "table"},
+			stdout: "PID TTY CMD\n  1 ?   /sbin/init splash\n 42 pts/0\n",
+			want:   `[{"CMD":"/sbin/init splash","PID":"1","TTY":"?"},{"PID":"42","TTY":"pts/0"}]`,
+		},
+		{
+			name:   "table with columns",
+			parser: &models.OutputParser{Type: "table", Columns: []string{"filesystem", "size", "mounted_on"}},
+			stdout: "Filesystem Size Mounted on\n/dev/sda1 20G /\n",
+			want:   `[{"filesystem":"/dev/sda1","mounted_on":"/","size":"20G"}]`,
+		},
+		{
+			name:   "table header only",
+			parser: &models.OutputParser{Type: "table"},
+			stdout: "NAME STATE\n",
+			want:   `[]`,
+		},
+		{
+			name:   "regex matches",
+			parser: &models.OutputParser{Type: "regex", Pattern: `(?m)^(?P<name>\w+):\s+(?P<total>\d+)(?:\s+(?P<used>\d+))?`},
+			stdout: "Mem: 7953 2134\nSwap: 2047\n",
+			want:   `[{"name":"Mem","total":"7953","used":"2134"},{"name":"Swap","total":"2047"}]`,
+		},
+		{
+			name:   "regex without matches",
+			parser: &models.OutputParser{Type: "regex", Pattern: `(?P<x>\d+)`},
+			stdout: "none",
+			want:   `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.parser, []byte(tt.stdout))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
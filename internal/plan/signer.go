@@ -0,0 +1,145 @@
+// Package plan signs execution plans into tokens that can be applied once
+package plan
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// Errors returned by Verify and Consume
+var (
+	ErrInvalid  = errors.New("invalid plan token")
+	ErrExpired  = errors.New("plan expired")
+	ErrApplied  = errors.New("plan already applied")
+	ErrIdentity = errors.New("plan was made for another identity")
+)
+
+// Signer issues plan tokens and accepts each of them once. Its key is
+// generated when it is created, so tokens don't outlive it.
+type Signer struct {
+	key []byte
+	ttl time.Duration
+
+	mu      sync.Mutex
+	applied map[string]time.Time // Plan IDs to their expiry
+	now     func() time.Time
+}
+
+// New creates a signer for tokens valid for ttl
+func New(ttl time.Duration) *Signer {
+	key := make([]byte, 32)
+	rand.Read(key)
+
+	return &Signer{
+		key:     key,
+		ttl:     ttl,
+		applied: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Sign assigns the plan an ID and expiry and returns its token: the base64
+// encoded plan, a ".", and the base64 encoded HMAC-SHA256 of the former
+func (s *Signer) Sign(plan *models.Plan) (string, error) {
+	now := s.now()
+	plan.ID = rand.Text()
+	plan.Created = now.UTC().Format(time.RFC3339)
+	plan.Expires = now.Add(s.ttl).UTC().Format(time.RFC3339)
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), nil
+}
+
+// Verify checks a token and returns its plan if the identity may apply it.
+// The plan isn't used up until it is consumed, so a run that is rejected
+// doesn't cost the caller their plan.
+func (s *Signer) Verify(token, identity string) (*models.Plan, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(payload)) {
+		return nil, ErrInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	var plan models.Plan
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&plan); err != nil {
+		return nil, ErrInvalid
+	}
+	expires, err := time.Parse(time.RFC3339, plan.Expires)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	if s.now().After(expires) {
+		return nil, ErrExpired
+	}
+	if plan.Requester != identity {
+		return nil, ErrIdentity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.applied[plan.ID]; ok {
+		return nil, ErrApplied
+	}
+
+	return &plan, nil
+}
+
+// Consume marks a verified plan as applied, failing if it already was or
+// it expired in the meantime. Each plan can be consumed once.
+func (s *Signer) Consume(plan *models.Plan) error {
+	expires, err := time.Parse(time.RFC3339, plan.Expires)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, e := range s.applied {
+		if now.After(e) {
+			delete(s.applied, id)
+		}
+	}
+
+	if now.After(expires) {
+		return ErrExpired
+	}
+	if _, ok := s.applied[plan.ID]; ok {
+		return ErrApplied
+	}
+	s.applied[plan.ID] = expires
+	return nil
+}
+
+// mac computes the signature of a token's payload
+func (s *Signer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
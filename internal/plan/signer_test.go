@@ -0,0 +1,98 @@
+package plan
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestSignVerifyConsume(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s := New(5 * time.Minute)
+	s.now = func() time.Time { return now }
+
+	token, err := s.Sign(&models.Plan{Command: "systemctl", Args: []string{"restart", "nginx"}, Timeout: 30})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	plan, err := s.Verify(token, "")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if plan.Command != "systemctl" || !slices.Equal(plan.Args, []string{"restart", "nginx"}) || plan.Expires != "2024-03-15T10:05:00Z" {
+		t.Errorf("Verify() = %+v", plan)
+	}
+
+	// Verifying alone doesn't use the plan up
+	if _, err := s.Verify(token, ""); err != nil {
+		t.Fatalf("second Verify() error = %v", err)
+	}
+
+	if err := s.Consume(plan); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if _, err := s.Verify(token, ""); !errors.Is(err, ErrApplied) {
+		t.Errorf("Verify() after Consume() error = %v, want ErrApplied", err)
+	}
+	if err := s.Consume(plan); !errors.Is(err, ErrApplied) {
+		t.Errorf("second Consume() error = %v, want ErrApplied", err)
+	}
+}
+
+func TestVerifyIdentity(t *testing.T) {
+	s := New(5 * time.Minute)
+	token, _ := s.Sign(&models.Plan{Command: "uptime", Requester: "alice"})
+
+	if _, err := s.Verify(token, "bob"); !errors.Is(err, ErrIdentity) {
+		t.Errorf("Verify() by another identity error = %v, want ErrIdentity", err)
+	}
+	if _, err := s.Verify(token, "alice"); err != nil {
+		t.Errorf("Verify() by the requester error = %v", err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	s := New(5 * time.Minute)
+	s.now = func() time.Time { return now }
+
+	token, _ := s.Sign(&models.Plan{Command: "uptime"})
+	now = now.Add(6 * time.Minute)
+	if _, err := s.Verify(token, ""); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() error = %v, want ErrExpired", err)
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	s := New(5 * time.Minute)
+	token, _ := s.Sign(&models.Plan{Command: "systemctl", Args: []string{"status", "nginx"}})
+	payload, sig, _ := strings.Cut(token, ".")
+
+	// A payload changed by the client, here the arguments
+	other, _ := New(5 * time.Minute).Sign(&models.Plan{Command: "systemctl", Args: []string{"stop", "nginx"}})
+	otherPayload, _, _ := strings.Cut(other, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no signature", token: payload},
+		{name: "changed payload", token: otherPayload + "." + sig},
+		{name: "other signer", token: other},
+		{name: "bad encoding", token: "!!." + sig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Verify(tt.token, ""); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Verify() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
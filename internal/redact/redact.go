@@ -0,0 +1,173 @@
+// Package redact masks secrets in command output
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultReplacement replaces matches of rules without a replacement
+const DefaultReplacement = "[REDACTED]"
+
+// Rule replaces every match of Pattern with Replacement, in which $1 or
+// ${name} refer to capture groups as in regexp.Regexp.Expand
+type Rule struct {
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement" json:"replacement,omitempty"`
+}
+
+// detector is a built-in rule for a common secret format
+type detector struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// detectors are referenced by name from the configuration. Where a secret
+// follows a label, the label is kept so the output stays readable.
+var detectors = map[string]detector{
+	"aws_access_key": {
+		re:          regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		replacement: "[REDACTED:aws_access_key]",
+	},
+	"github_token": {
+		re:          regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+		replacement: "[REDACTED:github_token]",
+	},
+	"slack_token": {
+		re:          regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+		replacement: "[REDACTED:slack_token]",
+	},
+	"jwt": {
+		re:          regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+		replacement: "[REDACTED:jwt]",
+	},
+	"bearer_token": {
+		re:          regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/-]{8,}=*`),
+		replacement: "${1}[REDACTED:bearer_token]",
+	},
+	"private_key": {
+		re:          regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|\z)`),
+		replacement: "[REDACTED:private_key]",
+	},
+	"password": {
+		re:          regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|api_?key|access_?token)["']?\s*[:=]\s*["']?)[^\s"',;]+`),
+		replacement: "${1}[REDACTED:password]",
+	},
+}
+
+// Detectors returns the names of the built-in detectors
+func Detectors() []string {
+	names := make([]string, 0, len(detectors))
+	for name := range detectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Redactor applies a list of rules in order
+type Redactor struct {
+	rules []detector
+}
+
+// New builds a redactor from built-in detector names followed by rules
+func New(detectorNames []string, rules []Rule) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, name := range detectorNames {
+		d, ok := detectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown detector: %s (available: %s)", name, strings.Join(Detectors(), ", "))
+		}
+		r.rules = append(r.rules, d)
+	}
+
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", rule.Pattern, err)
+		}
+		if re.MatchString("") {
+			return nil, fmt.Errorf("pattern %q matches the empty string", rule.Pattern)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultReplacement
+		}
+		r.rules = append(r.rules, detector{re: re, replacement: replacement})
+	}
+
+	return r, nil
+}
+
+// Redact returns data with all rules applied and the number of replacements.
+// data is returned unchanged if nothing matched. A nil Redactor does nothing.
+func (r *Redactor) Redact(data []byte) ([]byte, int) {
+	return r.redact(data, false)
+}
+
+// RedactLines redacts lines as a single text, so that secrets spanning
+// several lines are found, and returns the same number of lines. Lines
+// covered by a multi-line match are left empty after the replacement.
+func (r *Redactor) RedactLines(lines [][]byte) ([][]byte, int) {
+	if r == nil || len(lines) == 0 {
+		return lines, 0
+	}
+
+	data, count := r.redact(bytes.Join(lines, []byte("\n")), true)
+	if count == 0 {
+		return lines, 0
+	}
+
+	redacted := bytes.Split(data, []byte("\n"))
+	if len(redacted) != len(lines) {
+		// A replacement repeated a group containing a newline; fall back
+		// to redacting each line on its own
+		count = 0
+		for i := range lines {
+			var n int
+			lines[i], n = r.redact(lines[i], false)
+			count += n
+		}
+		return lines, count
+	}
+	return redacted, count
+}
+
+// redact applies the rules to data. If keepLines is set, every replacement
+// is followed by as many newlines as it removed.
+func (r *Redactor) redact(data []byte, keepLines bool) ([]byte, int) {
+	if r == nil {
+		return data, 0
+	}
+
+	count := 0
+	for _, rule := range r.rules {
+		matches := rule.re.FindAllSubmatchIndex(data, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var out []byte
+		last := 0
+		for _, m := range matches {
+			out = append(out, data[last:m[0]]...)
+			start := len(out)
+			out = rule.re.Expand(out, []byte(rule.replacement), data, m)
+			if keepLines {
+				removed := bytes.Count(data[m[0]:m[1]], []byte("\n")) - bytes.Count(out[start:], []byte("\n"))
+				for ; removed > 0; removed-- {
+					out = append(out, '\n')
+				}
+			}
+			last = m[1]
+		}
+		data = append(out, data[last:]...)
+		count += len(matches)
+	}
+
+	return data, count
+}
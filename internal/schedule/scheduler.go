@@ -0,0 +1,188 @@
+// Package schedule runs configured schedules at their cron times
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/cron"
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// RunFunc runs a schedule's command or runbook, returning an error if the
+// run did not succeed
+type RunFunc func(ctx context.Context, schedule *models.Schedule) error
+
+// SkipFunc is called when a run is dropped by the overlap policy
+type SkipFunc func(schedule *models.Schedule)
+
+// Scheduler runs schedules in the background until stopped
+type Scheduler struct {
+	jobs []*job
+	run  RunFunc
+	skip SkipFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// job is the state of one schedule
+type job struct {
+	schedule models.Schedule
+	cron     *cron.Schedule
+
+	mu     sync.Mutex
+	status models.ScheduleStatus
+	queued bool
+}
+
+// New creates a scheduler for schedules that were validated by the
+// configuration
+func New(schedules []models.Schedule, run RunFunc, skip SkipFunc) (*Scheduler, error) {
+	s := &Scheduler{
+		run:  run,
+		skip: skip,
+	}
+
+	for _, schedule := range schedules {
+		c, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron for schedule %s: %w", schedule.Name, err)
+		}
+		if schedule.Overlap == "" {
+			schedule.Overlap = models.OverlapSkip
+		}
+		s.jobs = append(s.jobs, &job{
+			schedule: schedule,
+			cron:     c,
+		})
+	}
+
+	return s, nil
+}
+
+// Start starts running the schedules
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop stops the schedules, cancelling runs in progress, and waits for
+// them to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Status returns the state of every schedule in configuration order
+func (s *Scheduler) Status() []models.ScheduleStatus {
+	statuses := make([]models.ScheduleStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		status := j.status
+		j.mu.Unlock()
+
+		status.Schedule = j.schedule
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// loop waits for each activation of a job and triggers it
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.cron.Next(time.Now())
+		if !next.IsZero() && j.schedule.Jitter > 0 {
+			next = next.Add(rand.N(time.Duration(j.schedule.Jitter) * time.Second))
+		}
+
+		j.mu.Lock()
+		j.status.NextRun = ""
+		if !next.IsZero() {
+			j.status.NextRun = next.Format(time.RFC3339)
+		}
+		j.mu.Unlock()
+
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.trigger(ctx, j)
+	}
+}
+
+// trigger starts a run of the job, or applies its overlap policy if the
+// previous run is still in progress
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.status.Running {
+		if j.schedule.Overlap == models.OverlapQueue && !j.queued {
+			j.queued = true
+			j.status.Queued = true
+			return
+		}
+		j.status.Skipped++
+		s.skip(&j.schedule)
+		return
+	}
+
+	j.status.Running = true
+	s.wg.Add(1)
+	go s.execute(ctx, j)
+}
+
+// execute runs the job, followed by its queued run if there is one
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		start := time.Now()
+		err := s.run(ctx, &j.schedule)
+
+		j.mu.Lock()
+		j.status.Runs++
+		j.status.LastRun = start.Format(time.RFC3339)
+		j.status.LastDuration = time.Since(start).String()
+		j.status.LastStatus = models.StepSucceeded
+		j.status.LastError = ""
+		if err != nil {
+			j.status.LastStatus = models.StepFailed
+			j.status.LastError = err.Error()
+		}
+
+		queued := j.queued && ctx.Err() == nil
+		j.queued = false
+		j.status.Queued = false
+		if !queued {
+			j.status.Running = false
+		}
+		j.mu.Unlock()
+
+		if !queued {
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestTriggerOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		overlap     string
+		wantRuns    int64
+		wantSkipped int64
+	}{
+		{name: "skip", overlap: models.OverlapSkip, wantRuns: 1, wantSkipped: 2},
+		{name: "queue keeps one run", overlap: models.OverlapQueue, wantRuns: 2, wantSkipped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			started := make(chan struct{}, 3)
+			run := func(ctx context.Context, schedule *models.Schedule) error {
+				started <- struct{}{}
+				<-release
+				return nil
+			}
+			var skipped int
+			skip := func(schedule *models.Schedule) { skipped++ }
+
+			s, err := New([]models.Schedule{{Name: "job", Cron: "@hourly", Command: "true", Overlap: tt.overlap}}, run, skip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j := s.jobs[0]
+
+			// The first trigger runs, the others overlap with it
+			ctx := context.Background()
+			s.trigger(ctx, j)
+			<-started
+			s.trigger(ctx, j)
+			s.trigger(ctx, j)
+			close(release)
+			s.wg.Wait()
+
+			status := s.Status()[0]
+			if status.Runs != tt.wantRuns || status.Skipped != tt.wantSkipped {
+				t.Errorf("runs = %d, skipped = %d, want %d, %d", status.Runs, status.Skipped, tt.wantRuns, tt.wantSkipped)
+			}
+			if int64(skipped) != tt.wantSkipped {
+				t.Errorf("skip called %d times, want %d", skipped, tt.wantSkipped)
+			}
+			if status.Running || status.Queued {
+				t.Errorf("running = %v, queued = %v after the runs finished", status.Running, status.Queued)
+			}
+			if status.LastStatus != models.StepSucceeded {
+				t.Errorf("last status = %q, want %q", status.LastStatus, models.StepSucceeded)
+			}
+		})
+	}
+}
+
+func TestExecuteRecordsFailure(t *testing.T) {
+	run := func(ctx context.Context, schedule *models.Schedule) error {
+		return errors.New("command failed with exit code 1")
+	}
+
+	s, err := New([]models.Schedule{{Name: "job", Cron: "@hourly", Command: "false"}}, run, func(*models.Schedule) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.trigger(context.Background(), s.jobs[0])
+	s.wg.Wait()
+
+	status := s.Status()[0]
+	if status.LastStatus != models.StepFailed || status.LastError != "command failed with exit code 1" {
+		t.Errorf("last status = %q, error = %q", status.LastStatus, status.LastError)
+	}
+	if status.Overlap != models.OverlapSkip {
+		t.Errorf("overlap = %q, want the default %q", status.Overlap, models.OverlapSkip)
+	}
+}
@@ -0,0 +1,72 @@
+package seccomp
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Offsets into struct seccomp_data
+	offsetNr   = 0
+	offsetArch = 4
+
+	x32SyscallBit = 0x40000000
+)
+
+// Filter builds a seccomp program. With allow set, only the given system
+// calls are permitted; otherwise the given system calls are denied. Denied
+// system calls fail with EPERM, while foreign architectures kill the process.
+func Filter(nrs []uint32, allow bool) []unix.SockFilter {
+	deny := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+	match, fallback := deny, uint32(unix.SECCOMP_RET_ALLOW)
+	if allow {
+		match, fallback = fallback, match
+	}
+
+	prog := []unix.SockFilter{
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetArch),
+		jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, auditArch, 1, 0),
+		stmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS),
+		stmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetNr),
+	}
+
+	if hasX32 {
+		prog = append(prog,
+			jump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, x32SyscallBit, 0, 1),
+			stmt(unix.BPF_RET|unix.BPF_K, deny),
+		)
+	}
+
+	for _, nr := range nrs {
+		prog = append(prog,
+			jump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 1),
+			stmt(unix.BPF_RET|unix.BPF_K, match),
+		)
+	}
+
+	return append(prog, stmt(unix.BPF_RET|unix.BPF_K, fallback))
+}
+
+// Install loads prog for the calling thread. The caller must have set
+// no_new_privs or hold CAP_SYS_ADMIN.
+func Install(prog []unix.SockFilter) error {
+	fprog := unix.SockFprog{
+		Len:    uint16(len(prog)),
+		Filter: &prog[0],
+	}
+	err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&fprog)), 0, 0)
+	if err != nil {
+		return &os.SyscallError{Syscall: "prctl PR_SET_SECCOMP", Err: err}
+	}
+	return nil
+}
+
+func stmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+func jump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
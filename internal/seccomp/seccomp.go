@@ -0,0 +1,72 @@
+// Package seccomp builds and installs seccomp-bpf system call filters
+package seccomp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// groups are shorthands for related system calls, referenced as "@name"
+var groups = map[string][]string{
+	"network": {
+		"socket", "socketpair", "connect", "bind", "listen", "accept", "accept4",
+		"sendto", "sendmsg", "sendmmsg", "recvfrom", "recvmsg", "recvmmsg",
+		"getsockopt", "setsockopt", "getsockname", "getpeername", "shutdown",
+	},
+	"mount": {
+		"mount", "umount2", "pivot_root", "move_mount", "open_tree", "fsopen",
+		"fsconfig", "fsmount", "fspick", "mount_setattr",
+	},
+	"debug": {
+		"ptrace", "process_vm_readv", "process_vm_writev", "kcmp",
+	},
+	"module": {
+		"init_module", "finit_module", "delete_module",
+	},
+	"reboot": {
+		"reboot", "kexec_load", "kexec_file_load",
+	},
+}
+
+// Resolve expands groups and maps system call names to their numbers on
+// the running architecture. Names the architecture lacks are errors, except
+// within groups, which list system calls across architectures.
+func Resolve(names []string) ([]uint32, error) {
+	if syscallNumbers == nil {
+		return nil, fmt.Errorf("seccomp is not supported on this platform")
+	}
+
+	seen := make(map[uint32]bool)
+	var nrs []uint32
+	add := func(nr uint32) {
+		if !seen[nr] {
+			seen[nr] = true
+			nrs = append(nrs, nr)
+		}
+	}
+
+	for _, name := range names {
+		if group, ok := strings.CutPrefix(name, "@"); ok {
+			members, ok := groups[group]
+			if !ok {
+				return nil, fmt.Errorf("unknown system call group: %s", name)
+			}
+			for _, member := range members {
+				if nr, ok := syscallNumbers[member]; ok {
+					add(nr)
+				}
+			}
+			continue
+		}
+
+		nr, ok := syscallNumbers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown system call: %s", name)
+		}
+		add(nr)
+	}
+
+	sort.Slice(nrs, func(i, j int) bool { return nrs[i] < nrs[j] })
+	return nrs, nil
+}
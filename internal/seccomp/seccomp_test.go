@@ -0,0 +1,53 @@
+package seccomp
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("seccomp is not supported on this platform")
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    int
+		wantErr bool
+	}{
+		{name: "single system call", names: []string{"ptrace"}, want: 1},
+		{name: "duplicates are removed", names: []string{"ptrace", "ptrace"}, want: 1},
+		{name: "group", names: []string{"@module"}, want: 3},
+		{name: "group overlapping a name", names: []string{"@debug", "ptrace"}, want: 4},
+		{name: "empty list", names: nil, want: 0},
+		{name: "unknown system call", names: []string{"no_such_syscall"}, wantErr: true},
+		{name: "unknown group", names: []string{"@nothing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nrs, err := Resolve(tt.names)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Resolve() expected error but got %v", nrs)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Resolve() unexpected error = %v", err)
+				return
+			}
+			if len(nrs) != tt.want {
+				t.Errorf("Resolve() returned %d system calls, want %d", len(nrs), tt.want)
+			}
+			for i := 1; i < len(nrs); i++ {
+				if nrs[i-1] >= nrs[i] {
+					t.Errorf("Resolve() result is not sorted: %v", nrs)
+				}
+			}
+		})
+	}
+}
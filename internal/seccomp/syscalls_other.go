@@ -0,0 +1,11 @@
+//go:build !linux || !(amd64 || arm64)
+
+package seccomp
+
+const (
+	auditArch = 0
+	hasX32    = false
+)
+
+// syscallNumbers is empty where seccomp filters are not supported
+var syscallNumbers map[string]uint32
@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+	"github.com/zinrai/sevalet/internal/window"
+)
+
+// Error codes of TimeError
+const (
+	ErrCodeOutsideWindow = "outside_window"
+	ErrCodeChangeFreeze  = "change_freeze"
+)
+
+// TimeError rejects a request outside its command's maintenance window or
+// during one of its change freezes
+type TimeError struct {
+	Code   string
+	Name   string    // The window or freeze
+	Reason string    // The freeze's reason
+	Next   time.Time // The next time the command is allowed, zero if never
+}
+
+func (e *TimeError) Error() string {
+	var msg string
+	if e.Code == ErrCodeChangeFreeze {
+		msg = fmt.Sprintf("change freeze %s in effect", e.Name)
+		if e.Reason != "" {
+			msg += ": " + e.Reason
+		}
+	} else {
+		msg = fmt.Sprintf("outside maintenance window %s", e.Name)
+	}
+
+	if e.Next.IsZero() {
+		return msg
+	}
+	return fmt.Sprintf("%s, next allowed at %s", msg, e.Next.Format(time.RFC3339))
+}
+
+// freeze is a parsed change freeze
+type freeze struct {
+	start, end time.Time
+	reason     string
+}
+
+// Calendar holds the maintenance windows and change freezes commands refer
+// to
+type Calendar struct {
+	windows map[string]*window.Window
+	freezes map[string]freeze
+}
+
+// NewCalendar parses windows and freezes
+func NewCalendar(windows []models.Window, freezes []models.Freeze) (*Calendar, error) {
+	c := &Calendar{
+		windows: make(map[string]*window.Window),
+		freezes: make(map[string]freeze),
+	}
+
+	for _, w := range windows {
+		parsed, err := w.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %s: %w", w.Name, err)
+		}
+		c.windows[w.Name] = parsed
+	}
+	for _, f := range freezes {
+		start, end, err := f.Period()
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze %s: %w", f.Name, err)
+		}
+		c.freezes[f.Name] = freeze{start: start, end: end, reason: f.Reason}
+	}
+
+	return c, nil
+}
+
+// ValidateTime verifies that a command may run at now. Otherwise it returns
+// a TimeError with the next time it may.
+func (c *Calendar) ValidateTime(command *models.Command, now time.Time) error {
+	var timeErr *TimeError
+	for _, name := range command.Freezes {
+		if f := c.freezes[name]; !now.Before(f.start) && now.Before(f.end) {
+			timeErr = &TimeError{Code: ErrCodeChangeFreeze, Name: name, Reason: f.reason}
+			break
+		}
+	}
+	w := c.windows[command.Window]
+	if timeErr == nil && w != nil && !w.Contains(now) {
+		timeErr = &TimeError{Code: ErrCodeOutsideWindow, Name: command.Window}
+	}
+	if timeErr == nil {
+		return nil
+	}
+
+	timeErr.Next = c.next(command, now)
+	if w != nil && !timeErr.Next.IsZero() {
+		timeErr.Next = timeErr.Next.In(w.Location())
+	}
+	return timeErr
+}
+
+// next returns the first time from t on that the command's freezes have
+// ended and its window is open, zero if there is none within a year
+func (c *Calendar) next(command *models.Command, t time.Time) time.Time {
+	limit := t.AddDate(1, 0, 0)
+	for t.Before(limit) {
+		moved := false
+		for _, name := range command.Freezes {
+			if f := c.freezes[name]; !t.Before(f.start) && t.Before(f.end) {
+				t = f.end
+				moved = true
+			}
+		}
+		if w := c.windows[command.Window]; w != nil && !w.Contains(t) {
+			t = w.Next(t)
+			moved = true
+		}
+		if !moved {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestValidateTime(t *testing.T) {
+	calendar, err := NewCalendar(
+		[]models.Window{{
+			Name:     "night",
+			Timezone: "UTC",
+			Ranges:   []models.WindowRange{{Start: "02:00", End: "05:00"}},
+		}},
+		[]models.Freeze{{
+			Name:   "year-end",
+			Start:  "2024-12-20T00:00:00Z",
+			End:    "2025-01-06T00:00:00Z",
+			Reason: "holidays",
+		}},
+	)
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	restart := &models.Command{Name: "systemctl", Window: "night", Freezes: []string{"year-end"}}
+	frozen := &models.Command{Name: "apt", Freezes: []string{"year-end"}}
+	free := &models.Command{Name: "uptime"}
+
+	tests := []struct {
+		name     string
+		command  *models.Command
+		now      string
+		wantCode string
+		wantNext string
+	}{
+		{name: "unrestricted", command: free, now: "2024-12-24T12:00:00Z"},
+		{name: "inside the window", command: restart, now: "2024-03-15T03:00:00Z"},
+		{
+			name:     "outside the window",
+			command:  restart,
+			now:      "2024-03-15T12:00:00Z",
+			wantCode: ErrCodeOutsideWindow,
+			wantNext: "2024-03-16T02:00:00Z",
+		},
+		{
+			name:     "frozen",
+			command:  frozen,
+			now:      "2024-12-24T12:00:00Z",
+			wantCode: ErrCodeChangeFreeze,
+			wantNext: "2025-01-06T00:00:00Z",
+		},
+		{
+			name:     "frozen inside the window",
+			command:  restart,
+			now:      "2024-12-24T03:00:00Z",
+			wantCode: ErrCodeChangeFreeze,
+			wantNext: "2025-01-06T02:00:00Z",
+		},
+		{name: "after the freeze", command: frozen, now: "2025-01-06T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, _ := time.Parse(time.RFC3339, tt.now)
+			err := calendar.ValidateTime(tt.command, now)
+
+			var timeErr *TimeError
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("ValidateTime() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &timeErr) {
+				t.Fatalf("ValidateTime() error = %v, want a TimeError", err)
+			}
+			if timeErr.Code != tt.wantCode || timeErr.Next.Format(time.RFC3339) != tt.wantNext {
+				t.Errorf("ValidateTime() = %s, %s, want %s, %s", timeErr.Code, timeErr.Next.Format(time.RFC3339), tt.wantCode, tt.wantNext)
+			}
+		})
+	}
+}
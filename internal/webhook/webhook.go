@@ -0,0 +1,298 @@
+// Package webhook sends signed completion payloads to callback URLs
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+// Headers sent with every payload. The signature is the hex encoded
+// HMAC-SHA256 of the timestamp, a ".", and the body.
+const (
+	HeaderDelivery  = "X-Sevalet-Delivery"
+	HeaderTimestamp = "X-Sevalet-Timestamp"
+	HeaderSignature = "X-Sevalet-Signature"
+)
+
+// maxDeliveries bounds the deliveries kept for inspection
+const maxDeliveries = 1000
+
+// AttemptFunc is called after each attempt with the delivery's state
+type AttemptFunc func(delivery models.Delivery, attempt models.DeliveryAttempt)
+
+// Dispatcher delivers payloads in the background and keeps a record of the
+// most recent deliveries
+type Dispatcher struct {
+	config    models.WebhookConfig
+	secret    []byte
+	client    *http.Client
+	onAttempt AttemptFunc
+
+	mu         sync.Mutex
+	deliveries map[string]*models.Delivery
+	order      []string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a dispatcher for a validated configuration
+func New(config *models.WebhookConfig, onAttempt AttemptFunc) (*Dispatcher, error) {
+	d := &Dispatcher{
+		config:     *config,
+		secret:     []byte(config.Secret),
+		onAttempt:  onAttempt,
+		deliveries: make(map[string]*models.Delivery),
+	}
+
+	if config.SecretFile != "" {
+		data, err := os.ReadFile(config.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook secret: %w", err)
+		}
+		d.secret = bytes.TrimSpace(data)
+		if len(d.secret) == 0 {
+			return nil, fmt.Errorf("webhook secret file %s is empty", config.SecretFile)
+		}
+	}
+
+	if d.config.MaxAttempts == 0 {
+		d.config.MaxAttempts = 5
+	}
+	if d.config.InitialBackoff == 0 {
+		d.config.InitialBackoff = 1
+	}
+	if d.config.MaxBackoff == 0 {
+		d.config.MaxBackoff = 60
+	}
+	if d.config.Timeout == 0 {
+		d.config.Timeout = 10
+	}
+
+	d.client = &http.Client{
+		Timeout: time.Duration(d.config.Timeout) * time.Second,
+		// Redirects could lead outside the allowed URLs
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	return d, nil
+}
+
+// Allows checks whether a callback URL is allowed
+func (d *Dispatcher) Allows(callbackURL string) bool {
+	return d != nil && d.config.Allows(callbackURL)
+}
+
+// Dispatch records a pending delivery and returns its ID. In the background
+// it calls data, then sends its result to the callback URL, retrying with
+// exponential backoff until the receiver accepts it or the attempts run
+// out.
+func (d *Dispatcher) Dispatch(event, callbackURL string, data func() any) string {
+	delivery := &models.Delivery{
+		ID:      rand.Text(),
+		Event:   event,
+		URL:     callbackURL,
+		Status:  models.DeliveryPending,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	d.mu.Lock()
+	d.deliveries[delivery.ID] = delivery
+	d.order = append(d.order, delivery.ID)
+	if len(d.order) > maxDeliveries {
+		delete(d.deliveries, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(delivery, data())
+	}()
+
+	return delivery.ID
+}
+
+// Delivery returns a copy of a delivery
+func (d *Dispatcher) Delivery(id string) (models.Delivery, bool) {
+	if d == nil {
+		return models.Delivery{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return models.Delivery{}, false
+	}
+	return copyDelivery(delivery), true
+}
+
+// Deliveries returns copies of the kept deliveries, most recent first
+func (d *Dispatcher) Deliveries() []models.Delivery {
+	if d == nil {
+		return []models.Delivery{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]models.Delivery, 0, len(d.order))
+	for i := len(d.order) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, copyDelivery(d.deliveries[d.order[i]]))
+	}
+	return deliveries
+}
+
+// Close stops retries and waits for deliveries in progress. Payloads still
+// being produced are sent once, without retries.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+}
+
+// deliver sends a payload until an attempt succeeds or gives up
+func (d *Dispatcher) deliver(delivery *models.Delivery, data any) {
+	body, err := json.Marshal(models.WebhookPayload{
+		Delivery:  delivery.ID,
+		Event:     delivery.Event,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		d.record(delivery, models.DeliveryAttempt{
+			Time:  time.Now().UTC().Format(time.RFC3339),
+			Error: fmt.Sprintf("failed to marshal payload: %v", err),
+		}, models.DeliveryFailed)
+		return
+	}
+
+	backoff := time.Duration(d.config.InitialBackoff) * time.Second
+	maxBackoff := time.Duration(d.config.MaxBackoff) * time.Second
+
+	for attempt := 1; ; attempt++ {
+		result, retry := d.attempt(delivery, body)
+
+		switch {
+		case !retry:
+			status := models.DeliveryDelivered
+			if result.Error != "" {
+				status = models.DeliveryFailed
+			}
+			d.record(delivery, result, status)
+			return
+		case attempt >= d.config.MaxAttempts || d.ctx.Err() != nil:
+			d.record(delivery, result, models.DeliveryFailed)
+			return
+		}
+		d.record(delivery, result, models.DeliveryPending)
+
+		select {
+		case <-d.ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// attempt sends a payload once. It reports whether the attempt should be
+// retried; errors that a retry won't fix are not.
+func (d *Dispatcher) attempt(delivery *models.Delivery, body []byte) (models.DeliveryAttempt, bool) {
+	start := time.Now()
+	result := models.DeliveryAttempt{
+		Time: start.UTC().Format(time.RFC3339),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result, false
+	}
+
+	timestamp := strconv.FormatInt(start.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(d.secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	result.Duration = time.Since(start).String()
+	if err != nil {
+		result.Error = err.Error()
+		return result, true
+	}
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return result, false
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		result.Error = fmt.Sprintf("receiver returned %s", resp.Status)
+		return result, true
+	default:
+		result.Error = fmt.Sprintf("receiver returned %s", resp.Status)
+		return result, false
+	}
+}
+
+// record adds an attempt to a delivery and reports it
+func (d *Dispatcher) record(delivery *models.Delivery, attempt models.DeliveryAttempt, status string) {
+	d.mu.Lock()
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.Status = status
+	snapshot := copyDelivery(delivery)
+	d.mu.Unlock()
+
+	if d.onAttempt != nil {
+		d.onAttempt(snapshot, attempt)
+	}
+}
+
+// Sign returns the hex encoded signature of a payload
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header against a payload, for receivers
+func Verify(secret []byte, timestamp string, body []byte, signature string) bool {
+	hexSig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(hexSig), []byte(Sign(secret, timestamp, body)))
+}
+
+// copyDelivery copies a delivery so it can be used outside the lock
+func copyDelivery(delivery *models.Delivery) models.Delivery {
+	c := *delivery
+	c.Attempts = append([]models.DeliveryAttempt(nil), delivery.Attempts...)
+	return c
+}
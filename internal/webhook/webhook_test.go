@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zinrai/sevalet/internal/models"
+)
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // Status returned for each attempt, the last one repeated
+		wantStatus   string
+		wantAttempts int
+	}{
+		{name: "delivered", statuses: []int{http.StatusNoContent}, wantStatus: models.DeliveryDelivered, wantAttempts: 1},
+		{name: "retried after server error", statuses: []int{http.StatusBadGateway, http.StatusOK}, wantStatus: models.DeliveryDelivered, wantAttempts: 2},
+		{name: "client error is not retried", statuses: []int{http.StatusBadRequest}, wantStatus: models.DeliveryFailed, wantAttempts: 1},
+		{name: "attempts run out", statuses: []int{http.StatusServiceUnavailable}, wantStatus: models.DeliveryFailed, wantAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := []byte("s3cret")
+			var calls atomic.Int32
+			var badSignature atomic.Bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !Verify(secret, r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)) {
+					badSignature.Store(true)
+				}
+				n := int(calls.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			var attempts atomic.Int32
+			d, err := New(&models.WebhookConfig{
+				AllowedURLs: []string{srv.URL + "/"},
+				Secret:      string(secret),
+				MaxAttempts: 2,
+			}, func(models.Delivery, models.DeliveryAttempt) { attempts.Add(1) })
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id := d.Dispatch("test.completed", srv.URL+"/hook", func() any { return map[string]string{"k": "v"} })
+			d.wg.Wait()
+
+			delivery, ok := d.Delivery(id)
+			if !ok {
+				t.Fatalf("delivery %s not found", id)
+			}
+			if delivery.Status != tt.wantStatus || len(delivery.Attempts) != tt.wantAttempts {
+				t.Errorf("status = %s with %d attempts, want %s with %d", delivery.Status, len(delivery.Attempts), tt.wantStatus, tt.wantAttempts)
+			}
+			if int(attempts.Load()) != tt.wantAttempts {
+				t.Errorf("attempt callback called %d times, want %d", attempts.Load(), tt.wantAttempts)
+			}
+			if badSignature.Load() {
+				t.Error("receiver got an invalid signature")
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"event":"test"}`)
+	sig := "sha256=" + Sign(secret, "1700000000", body)
+
+	if !Verify(secret, "1700000000", body, sig) {
+		t.Error("Verify() rejected a valid signature")
+	}
+	if Verify(secret, "1700000001", body, sig) {
+		t.Error("Verify() accepted a different timestamp")
+	}
+	if Verify([]byte("other"), "1700000000", body, sig) {
+		t.Error("Verify() accepted a different secret")
+	}
+	if Verify(secret, "1700000000", body, Sign(secret, "1700000000", body)) {
+		t.Error("Verify() accepted a signature without the sha256= prefix")
+	}
+}
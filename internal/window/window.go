@@ -0,0 +1,146 @@
+// Package window decides whether times fall into recurring weekly time
+// windows, such as maintenance windows
+package window
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Range is a daily time range on some days of the week. A range that ends
+// at or before its start ends on the next day, so it can span midnight.
+type Range struct {
+	days       []time.Weekday // The days the range starts on, every day if empty
+	start, end clock
+}
+
+// clock is a time of day
+type clock struct {
+	hour, minute int
+}
+
+// weekdays maps day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseRange parses a range starting on days (three-letter names, every day
+// if empty) at start and ending at end, both as "HH:MM"
+func ParseRange(days []string, start, end string) (Range, error) {
+	var r Range
+	for _, day := range days {
+		d, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return Range{}, fmt.Errorf("invalid day %q", day)
+		}
+		r.days = append(r.days, d)
+	}
+
+	var err error
+	if r.start, err = parseClock(start); err != nil {
+		return Range{}, fmt.Errorf("invalid start: %w", err)
+	}
+	if r.end, err = parseClock(end); err != nil {
+		return Range{}, fmt.Errorf("invalid end: %w", err)
+	}
+
+	return r, nil
+}
+
+// parseClock parses a time of day as "HH:MM"
+func parseClock(s string) (clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return clock{}, fmt.Errorf("%q is not a time of day as HH:MM", s)
+	}
+	return clock{hour: t.Hour(), minute: t.Minute()}, nil
+}
+
+// Window is a set of ranges in a time zone
+type Window struct {
+	location *time.Location
+	ranges   []Range
+}
+
+// New creates a window of ranges in a time zone, such as "Europe/Berlin".
+// An empty time zone is the local one.
+func New(timezone string, ranges []Range) (*Window, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ranges specified")
+	}
+
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	return &Window{location: location, ranges: ranges}, nil
+}
+
+// Location returns the window's time zone
+func (w *Window) Location() *time.Location {
+	return w.location
+}
+
+// Contains reports whether t falls into the window
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.location)
+
+	// A range that contains t started today or, spanning midnight, yesterday
+	for _, r := range w.ranges {
+		for offset := 0; offset >= -1; offset-- {
+			start, end, ok := r.on(t, offset)
+			if ok && !t.Before(start) && t.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Next returns t if it falls into the window, else the next time the window
+// opens after t
+func (w *Window) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+
+	t = t.In(w.location)
+	var next time.Time
+	for _, r := range w.ranges {
+		for offset := 0; offset <= 7; offset++ {
+			start, _, ok := r.on(t, offset)
+			if ok && start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next
+}
+
+// on returns the range's start and end on the day offset days from t's,
+// and whether the range starts on that day
+func (r Range) on(t time.Time, offset int) (time.Time, time.Time, bool) {
+	year, month, day := t.Date()
+	start := time.Date(year, month, day+offset, r.start.hour, r.start.minute, 0, 0, t.Location())
+	if len(r.days) > 0 && !slices.Contains(r.days, start.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	end := time.Date(year, month, day+offset, r.end.hour, r.end.minute, 0, 0, t.Location())
+	if !end.After(start) {
+		end = time.Date(year, month, day+offset+1, r.end.hour, r.end.minute, 0, 0, t.Location())
+	}
+	return start, end, true
+}
@@ -0,0 +1,107 @@
+package window
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		days       []string
+		start, end string
+		wantErr    bool
+	}{
+		{name: "every day", start: "02:00", end: "05:00"},
+		{name: "weekdays", days: []string{"mon", "Tue", "WED"}, start: "22:00", end: "02:00"},
+		{name: "whole day", days: []string{"sun"}, start: "00:00", end: "00:00"},
+		{name: "invalid day", days: []string{"monday"}, start: "02:00", end: "05:00", wantErr: true},
+		{name: "invalid start", start: "2am", end: "05:00", wantErr: true},
+		{name: "invalid end", start: "02:00", end: "24:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRange(tt.days, tt.start, tt.end); (err != nil) != tt.wantErr {
+				t.Errorf("ParseRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	night, _ := ParseRange([]string{"mon", "tue", "wed", "thu", "fri"}, "02:00", "05:00")
+	weekend, _ := ParseRange([]string{"sat"}, "22:00", "06:00")
+	w, err := New("Europe/Berlin", []Range{night, weekend})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	berlin := w.Location()
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		contains bool
+		next     time.Time
+	}{
+		{
+			name:     "inside",
+			t:        time.Date(2024, 3, 13, 3, 0, 0, 0, berlin), // Wednesday
+			contains: true,
+			next:     time.Date(2024, 3, 13, 3, 0, 0, 0, berlin),
+		},
+		{
+			name: "before the start",
+			t:    time.Date(2024, 3, 13, 1, 59, 0, 0, berlin),
+			next: time.Date(2024, 3, 13, 2, 0, 0, 0, berlin),
+		},
+		{
+			name: "at the end",
+			t:    time.Date(2024, 3, 13, 5, 0, 0, 0, berlin),
+			next: time.Date(2024, 3, 14, 2, 0, 0, 0, berlin),
+		},
+		{
+			name: "friday afternoon",
+			t:    time.Date(2024, 3, 15, 14, 0, 0, 0, berlin),
+			next: time.Date(2024, 3, 16, 22, 0, 0, 0, berlin),
+		},
+		{
+			name:     "past midnight",
+			t:        time.Date(2024, 3, 17, 5, 30, 0, 0, berlin), // Sunday
+			contains: true,
+			next:     time.Date(2024, 3, 17, 5, 30, 0, 0, berlin),
+		},
+		{
+			name: "sunday",
+			t:    time.Date(2024, 3, 17, 12, 0, 0, 0, berlin),
+			next: time.Date(2024, 3, 18, 2, 0, 0, 0, berlin),
+		},
+		{
+			name:     "other time zone",
+			t:        time.Date(2024, 3, 13, 2, 30, 0, 0, time.UTC), // 03:30 in Berlin
+			contains: true,
+			next:     time.Date(2024, 3, 13, 2, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.Contains(tt.t); got != tt.contains {
+				t.Errorf("Contains() = %v, want %v", got, tt.contains)
+			}
+			if got := w.Next(tt.t); !got.Equal(tt.next) {
+				t.Errorf("Next() = %v, want %v", got, tt.next)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	r, _ := ParseRange(nil, "02:00", "05:00")
+	if _, err := New("Mars/Olympus_Mons", []Range{r}); err == nil {
+		t.Error("New() with an unknown time zone succeeded")
+	}
+	if _, err := New("UTC", nil); err == nil {
+		t.Error("New() without ranges succeeded")
+	}
+}
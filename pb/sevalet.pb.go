@@ -26,6 +26,9 @@ type ExecuteRequest struct {
 	Command       string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
 	Args          []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
 	Timeout       int32                  `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	OutputMode    string                 `protobuf:"bytes,4,opt,name=output_mode,json=outputMode,proto3" json:"output_mode,omitempty"`
+	Filter        *OutputFilter          `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	Identity      string                 `protobuf:"bytes,6,opt,name=identity,proto3" json:"identity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,21 +84,139 @@ func (x *ExecuteRequest) GetTimeout() int32 {
 	return 0
 }
 
-type ExecuteResponse struct {
+func (x *ExecuteRequest) GetOutputMode() string {
+	if x != nil {
+		return x.OutputMode
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetFilter() *OutputFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ExecuteRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type OutputFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	ExitCode      int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	Stdout        string                 `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`
-	Stderr        string                 `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
-	ExecutionTime string                 `protobuf:"bytes,5,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Head          int32                  `protobuf:"varint,1,opt,name=head,proto3" json:"head,omitempty"`
+	Tail          int32                  `protobuf:"varint,2,opt,name=tail,proto3" json:"tail,omitempty"`
+	From          int32                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            int32                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`
+	Match         string                 `protobuf:"bytes,5,opt,name=match,proto3" json:"match,omitempty"`
+	Regex         string                 `protobuf:"bytes,6,opt,name=regex,proto3" json:"regex,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *OutputFilter) Reset() {
+	*x = OutputFilter{}
+	mi := &file_sevalet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputFilter) ProtoMessage() {}
+
+func (x *OutputFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputFilter.ProtoReflect.Descriptor instead.
+func (*OutputFilter) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OutputFilter) GetHead() int32 {
+	if x != nil {
+		return x.Head
+	}
+	return 0
+}
+
+func (x *OutputFilter) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *OutputFilter) GetFrom() int32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *OutputFilter) GetTo() int32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *OutputFilter) GetMatch() string {
+	if x != nil {
+		return x.Match
+	}
+	return ""
+}
+
+func (x *OutputFilter) GetRegex() string {
+	if x != nil {
+		return x.Regex
+	}
+	return ""
+}
+
+type ExecuteResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Success         bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ExitCode        int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout          []byte                 `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr          []byte                 `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExecutionTime   string                 `protobuf:"bytes,5,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LimitExceeded   string                 `protobuf:"bytes,7,opt,name=limit_exceeded,json=limitExceeded,proto3" json:"limit_exceeded,omitempty"`
+	PeakMemoryBytes uint64                 `protobuf:"varint,8,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+	CpuTime         string                 `protobuf:"bytes,9,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	Output          []*OutputLine          `protobuf:"bytes,10,rep,name=output,proto3" json:"output,omitempty"`
+	Parsed          []byte                 `protobuf:"bytes,11,opt,name=parsed,proto3" json:"parsed,omitempty"`
+	ParseError      string                 `protobuf:"bytes,12,opt,name=parse_error,json=parseError,proto3" json:"parse_error,omitempty"`
+	Succeeded       bool                   `protobuf:"varint,13,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Cached          bool                   `protobuf:"varint,14,opt,name=cached,proto3" json:"cached,omitempty"`
+	CacheAge        string                 `protobuf:"bytes,15,opt,name=cache_age,json=cacheAge,proto3" json:"cache_age,omitempty"`
+	Approval        *Approval              `protobuf:"bytes,16,opt,name=approval,proto3" json:"approval,omitempty"`
+	ErrorCode       string                 `protobuf:"bytes,17,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	NextAllowed     string                 `protobuf:"bytes,18,opt,name=next_allowed,json=nextAllowed,proto3" json:"next_allowed,omitempty"`
+	TruncatedLines  int32                  `protobuf:"varint,19,opt,name=truncated_lines,json=truncatedLines,proto3" json:"truncated_lines,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *ExecuteResponse) Reset() {
 	*x = ExecuteResponse{}
-	mi := &file_sevalet_proto_msgTypes[1]
+	mi := &file_sevalet_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -107,7 +228,7 @@ func (x *ExecuteResponse) String() string {
 func (*ExecuteResponse) ProtoMessage() {}
 
 func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_sevalet_proto_msgTypes[1]
+	mi := &file_sevalet_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -120,7 +241,7 @@ func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
 func (*ExecuteResponse) Descriptor() ([]byte, []int) {
-	return file_sevalet_proto_rawDescGZIP(), []int{1}
+	return file_sevalet_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *ExecuteResponse) GetSuccess() bool {
@@ -137,18 +258,18 @@ func (x *ExecuteResponse) GetExitCode() int32 {
 	return 0
 }
 
-func (x *ExecuteResponse) GetStdout() string {
+func (x *ExecuteResponse) GetStdout() []byte {
 	if x != nil {
 		return x.Stdout
 	}
-	return ""
+	return nil
 }
 
-func (x *ExecuteResponse) GetStderr() string {
+func (x *ExecuteResponse) GetStderr() []byte {
 	if x != nil {
 		return x.Stderr
 	}
-	return ""
+	return nil
 }
 
 func (x *ExecuteResponse) GetExecutionTime() string {
@@ -165,24 +286,1921 @@ func (x *ExecuteResponse) GetErrorMessage() string {
 	return ""
 }
 
-var File_sevalet_proto protoreflect.FileDescriptor
+func (x *ExecuteResponse) GetLimitExceeded() string {
+	if x != nil {
+		return x.LimitExceeded
+	}
+	return ""
+}
 
-const file_sevalet_proto_rawDesc = "" +
-	"\n" +
-	"\rsevalet.proto\x12\asevalet\"X\n" +
-	"\x0eExecuteRequest\x12\x18\n" +
-	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
-	"\x04args\x18\x02 \x03(\tR\x04args\x12\x18\n" +
-	"\atimeout\x18\x03 \x01(\x05R\atimeout\"\xc4\x01\n" +
-	"\x0fExecuteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
-	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x16\n" +
-	"\x06stdout\x18\x03 \x01(\tR\x06stdout\x12\x16\n" +
-	"\x06stderr\x18\x04 \x01(\tR\x06stderr\x12%\n" +
-	"\x0eexecution_time\x18\x05 \x01(\tR\rexecutionTime\x12#\n" +
-	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage2O\n" +
+func (x *ExecuteResponse) GetPeakMemoryBytes() uint64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
+func (x *ExecuteResponse) GetCpuTime() string {
+	if x != nil {
+		return x.CpuTime
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetOutput() []*OutputLine {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetParsed() []byte {
+	if x != nil {
+		return x.Parsed
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetParseError() string {
+	if x != nil {
+		return x.ParseError
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *ExecuteResponse) GetCacheAge() string {
+	if x != nil {
+		return x.CacheAge
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetApproval() *Approval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetNextAllowed() string {
+	if x != nil {
+		return x.NextAllowed
+	}
+	return ""
+}
+
+func (x *ExecuteResponse) GetTruncatedLines() int32 {
+	if x != nil {
+		return x.TruncatedLines
+	}
+	return 0
+}
+
+type OutputLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stream        string                 `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	OffsetNs      int64                  `protobuf:"varint,2,opt,name=offset_ns,json=offsetNs,proto3" json:"offset_ns,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutputLine) Reset() {
+	*x = OutputLine{}
+	mi := &file_sevalet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputLine) ProtoMessage() {}
+
+func (x *OutputLine) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputLine.ProtoReflect.Descriptor instead.
+func (*OutputLine) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OutputLine) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *OutputLine) GetOffsetNs() int64 {
+	if x != nil {
+		return x.OffsetNs
+	}
+	return 0
+}
+
+func (x *OutputLine) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type PipelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stages        []*PipelineStage       `protobuf:"bytes,1,rep,name=stages,proto3" json:"stages,omitempty"`
+	Timeout       int32                  `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Identity      string                 `protobuf:"bytes,3,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PipelineRequest) Reset() {
+	*x = PipelineRequest{}
+	mi := &file_sevalet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PipelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineRequest) ProtoMessage() {}
+
+func (x *PipelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineRequest.ProtoReflect.Descriptor instead.
+func (*PipelineRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PipelineRequest) GetStages() []*PipelineStage {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *PipelineRequest) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *PipelineRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type PipelineStage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Command       string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PipelineStage) Reset() {
+	*x = PipelineStage{}
+	mi := &file_sevalet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PipelineStage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineStage) ProtoMessage() {}
+
+func (x *PipelineStage) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineStage.ProtoReflect.Descriptor instead.
+func (*PipelineStage) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PipelineStage) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *PipelineStage) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type PipelineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Succeeded     bool                   `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Stdout        []byte                 `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	ExecutionTime string                 `protobuf:"bytes,4,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,5,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Stages        []*StageResult         `protobuf:"bytes,6,rep,name=stages,proto3" json:"stages,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,7,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	NextAllowed   string                 `protobuf:"bytes,8,opt,name=next_allowed,json=nextAllowed,proto3" json:"next_allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PipelineResponse) Reset() {
+	*x = PipelineResponse{}
+	mi := &file_sevalet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PipelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PipelineResponse) ProtoMessage() {}
+
+func (x *PipelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PipelineResponse.ProtoReflect.Descriptor instead.
+func (*PipelineResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PipelineResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PipelineResponse) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *PipelineResponse) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *PipelineResponse) GetExecutionTime() string {
+	if x != nil {
+		return x.ExecutionTime
+	}
+	return ""
+}
+
+func (x *PipelineResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *PipelineResponse) GetStages() []*StageResult {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *PipelineResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *PipelineResponse) GetNextAllowed() string {
+	if x != nil {
+		return x.NextAllowed
+	}
+	return ""
+}
+
+type StageResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Command         string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	ExitCode        int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stderr          []byte                 `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Signal          string                 `protobuf:"bytes,4,opt,name=signal,proto3" json:"signal,omitempty"`
+	Succeeded       bool                   `protobuf:"varint,5,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	LimitExceeded   string                 `protobuf:"bytes,7,opt,name=limit_exceeded,json=limitExceeded,proto3" json:"limit_exceeded,omitempty"`
+	PeakMemoryBytes uint64                 `protobuf:"varint,8,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+	CpuTime         string                 `protobuf:"bytes,9,opt,name=cpu_time,json=cpuTime,proto3" json:"cpu_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StageResult) Reset() {
+	*x = StageResult{}
+	mi := &file_sevalet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageResult) ProtoMessage() {}
+
+func (x *StageResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageResult.ProtoReflect.Descriptor instead.
+func (*StageResult) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StageResult) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *StageResult) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *StageResult) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *StageResult) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *StageResult) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *StageResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *StageResult) GetLimitExceeded() string {
+	if x != nil {
+		return x.LimitExceeded
+	}
+	return ""
+}
+
+func (x *StageResult) GetPeakMemoryBytes() uint64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
+func (x *StageResult) GetCpuTime() string {
+	if x != nil {
+		return x.CpuTime
+	}
+	return ""
+}
+
+type RunbookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Identity      string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunbookRequest) Reset() {
+	*x = RunbookRequest{}
+	mi := &file_sevalet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunbookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunbookRequest) ProtoMessage() {}
+
+func (x *RunbookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunbookRequest.ProtoReflect.Descriptor instead.
+func (*RunbookRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RunbookRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RunbookRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type RunbookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Succeeded     bool                   `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	ExecutionTime string                 `protobuf:"bytes,3,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Steps         []*StepResult          `protobuf:"bytes,5,rep,name=steps,proto3" json:"steps,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,6,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	NextAllowed   string                 `protobuf:"bytes,7,opt,name=next_allowed,json=nextAllowed,proto3" json:"next_allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunbookResponse) Reset() {
+	*x = RunbookResponse{}
+	mi := &file_sevalet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunbookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunbookResponse) ProtoMessage() {}
+
+func (x *RunbookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunbookResponse.ProtoReflect.Descriptor instead.
+func (*RunbookResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RunbookResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RunbookResponse) GetSucceeded() bool {
+	if x != nil {
+		return x.Succeeded
+	}
+	return false
+}
+
+func (x *RunbookResponse) GetExecutionTime() string {
+	if x != nil {
+		return x.ExecutionTime
+	}
+	return ""
+}
+
+func (x *RunbookResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *RunbookResponse) GetSteps() []*StepResult {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *RunbookResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *RunbookResponse) GetNextAllowed() string {
+	if x != nil {
+		return x.NextAllowed
+	}
+	return ""
+}
+
+type StepResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Compensates   string                 `protobuf:"bytes,5,opt,name=compensates,proto3" json:"compensates,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout        []byte                 `protobuf:"bytes,7,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        []byte                 `protobuf:"bytes,8,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExecutionTime string                 `protobuf:"bytes,9,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,10,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepResult) Reset() {
+	*x = StepResult{}
+	mi := &file_sevalet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepResult) ProtoMessage() {}
+
+func (x *StepResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepResult.ProtoReflect.Descriptor instead.
+func (*StepResult) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StepResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StepResult) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *StepResult) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *StepResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StepResult) GetCompensates() string {
+	if x != nil {
+		return x.Compensates
+	}
+	return ""
+}
+
+func (x *StepResult) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *StepResult) GetStdout() []byte {
+	if x != nil {
+		return x.Stdout
+	}
+	return nil
+}
+
+func (x *StepResult) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *StepResult) GetExecutionTime() string {
+	if x != nil {
+		return x.ExecutionTime
+	}
+	return ""
+}
+
+func (x *StepResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_sevalet_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{11}
+}
+
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*ScheduleStatus      `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_sevalet_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*ScheduleStatus {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type WaitScheduleCompletionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitScheduleCompletionsRequest) Reset() {
+	*x = WaitScheduleCompletionsRequest{}
+	mi := &file_sevalet_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitScheduleCompletionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitScheduleCompletionsRequest) ProtoMessage() {}
+
+func (x *WaitScheduleCompletionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitScheduleCompletionsRequest.ProtoReflect.Descriptor instead.
+func (*WaitScheduleCompletionsRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{13}
+}
+
+type WaitScheduleCompletionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Completions   []*ScheduleCompletion  `protobuf:"bytes,1,rep,name=completions,proto3" json:"completions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WaitScheduleCompletionsResponse) Reset() {
+	*x = WaitScheduleCompletionsResponse{}
+	mi := &file_sevalet_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitScheduleCompletionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitScheduleCompletionsResponse) ProtoMessage() {}
+
+func (x *WaitScheduleCompletionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitScheduleCompletionsResponse.ProtoReflect.Descriptor instead.
+func (*WaitScheduleCompletionsResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WaitScheduleCompletionsResponse) GetCompletions() []*ScheduleCompletion {
+	if x != nil {
+		return x.Completions
+	}
+	return nil
+}
+
+type ScheduleCompletion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      string                 `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	CallbackUrl   string                 `protobuf:"bytes,2,opt,name=callback_url,json=callbackUrl,proto3" json:"callback_url,omitempty"`
+	Started       string                 `protobuf:"bytes,3,opt,name=started,proto3" json:"started,omitempty"`
+	Duration      string                 `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleCompletion) Reset() {
+	*x = ScheduleCompletion{}
+	mi := &file_sevalet_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleCompletion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleCompletion) ProtoMessage() {}
+
+func (x *ScheduleCompletion) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleCompletion.ProtoReflect.Descriptor instead.
+func (*ScheduleCompletion) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ScheduleCompletion) GetSchedule() string {
+	if x != nil {
+		return x.Schedule
+	}
+	return ""
+}
+
+func (x *ScheduleCompletion) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+func (x *ScheduleCompletion) GetStarted() string {
+	if x != nil {
+		return x.Started
+	}
+	return ""
+}
+
+func (x *ScheduleCompletion) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *ScheduleCompletion) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScheduleCompletion) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ScheduleStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Cron          string                 `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	Runbook       string                 `protobuf:"bytes,5,opt,name=runbook,proto3" json:"runbook,omitempty"`
+	Timeout       int32                  `protobuf:"varint,6,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Jitter        int32                  `protobuf:"varint,7,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	Overlap       string                 `protobuf:"bytes,8,opt,name=overlap,proto3" json:"overlap,omitempty"`
+	Running       bool                   `protobuf:"varint,9,opt,name=running,proto3" json:"running,omitempty"`
+	Queued        bool                   `protobuf:"varint,10,opt,name=queued,proto3" json:"queued,omitempty"`
+	LastRun       string                 `protobuf:"bytes,11,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"`
+	LastStatus    string                 `protobuf:"bytes,12,opt,name=last_status,json=lastStatus,proto3" json:"last_status,omitempty"`
+	LastDuration  string                 `protobuf:"bytes,13,opt,name=last_duration,json=lastDuration,proto3" json:"last_duration,omitempty"`
+	LastError     string                 `protobuf:"bytes,14,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	NextRun       string                 `protobuf:"bytes,15,opt,name=next_run,json=nextRun,proto3" json:"next_run,omitempty"`
+	Runs          int64                  `protobuf:"varint,16,opt,name=runs,proto3" json:"runs,omitempty"`
+	Skipped       int64                  `protobuf:"varint,17,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	CallbackUrl   string                 `protobuf:"bytes,18,opt,name=callback_url,json=callbackUrl,proto3" json:"callback_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleStatus) Reset() {
+	*x = ScheduleStatus{}
+	mi := &file_sevalet_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleStatus) ProtoMessage() {}
+
+func (x *ScheduleStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleStatus.ProtoReflect.Descriptor instead.
+func (*ScheduleStatus) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ScheduleStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ScheduleStatus) GetRunbook() string {
+	if x != nil {
+		return x.Runbook
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *ScheduleStatus) GetJitter() int32 {
+	if x != nil {
+		return x.Jitter
+	}
+	return 0
+}
+
+func (x *ScheduleStatus) GetOverlap() string {
+	if x != nil {
+		return x.Overlap
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ScheduleStatus) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
+func (x *ScheduleStatus) GetLastRun() string {
+	if x != nil {
+		return x.LastRun
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetLastStatus() string {
+	if x != nil {
+		return x.LastStatus
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetLastDuration() string {
+	if x != nil {
+		return x.LastDuration
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetNextRun() string {
+	if x != nil {
+		return x.NextRun
+	}
+	return ""
+}
+
+func (x *ScheduleStatus) GetRuns() int64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *ScheduleStatus) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ScheduleStatus) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+type Approval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+	Requester     string                 `protobuf:"bytes,4,opt,name=requester,proto3" json:"requester,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Required      int32                  `protobuf:"varint,6,opt,name=required,proto3" json:"required,omitempty"`
+	Votes         []*ApprovalVote        `protobuf:"bytes,7,rep,name=votes,proto3" json:"votes,omitempty"`
+	Created       string                 `protobuf:"bytes,8,opt,name=created,proto3" json:"created,omitempty"`
+	Expires       string                 `protobuf:"bytes,9,opt,name=expires,proto3" json:"expires,omitempty"`
+	Result        *ExecuteResponse       `protobuf:"bytes,10,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Approval) Reset() {
+	*x = Approval{}
+	mi := &file_sevalet_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Approval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Approval) ProtoMessage() {}
+
+func (x *Approval) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Approval.ProtoReflect.Descriptor instead.
+func (*Approval) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Approval) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Approval) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *Approval) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Approval) GetRequester() string {
+	if x != nil {
+		return x.Requester
+	}
+	return ""
+}
+
+func (x *Approval) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Approval) GetRequired() int32 {
+	if x != nil {
+		return x.Required
+	}
+	return 0
+}
+
+func (x *Approval) GetVotes() []*ApprovalVote {
+	if x != nil {
+		return x.Votes
+	}
+	return nil
+}
+
+func (x *Approval) GetCreated() string {
+	if x != nil {
+		return x.Created
+	}
+	return ""
+}
+
+func (x *Approval) GetExpires() string {
+	if x != nil {
+		return x.Expires
+	}
+	return ""
+}
+
+func (x *Approval) GetResult() *ExecuteResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ApprovalVote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	Approve       bool                   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	Comment       string                 `protobuf:"bytes,3,opt,name=comment,proto3" json:"comment,omitempty"`
+	Time          string                 `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalVote) Reset() {
+	*x = ApprovalVote{}
+	mi := &file_sevalet_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalVote) ProtoMessage() {}
+
+func (x *ApprovalVote) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalVote.ProtoReflect.Descriptor instead.
+func (*ApprovalVote) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ApprovalVote) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *ApprovalVote) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+func (x *ApprovalVote) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *ApprovalVote) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+type GetApprovalRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetApprovalRequest) Reset() {
+	*x = GetApprovalRequest{}
+	mi := &file_sevalet_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetApprovalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetApprovalRequest) ProtoMessage() {}
+
+func (x *GetApprovalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetApprovalRequest.ProtoReflect.Descriptor instead.
+func (*GetApprovalRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetApprovalRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListApprovalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListApprovalsRequest) Reset() {
+	*x = ListApprovalsRequest{}
+	mi := &file_sevalet_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListApprovalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListApprovalsRequest) ProtoMessage() {}
+
+func (x *ListApprovalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListApprovalsRequest.ProtoReflect.Descriptor instead.
+func (*ListApprovalsRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{20}
+}
+
+type ListApprovalsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Approvals     []*Approval            `protobuf:"bytes,1,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListApprovalsResponse) Reset() {
+	*x = ListApprovalsResponse{}
+	mi := &file_sevalet_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListApprovalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListApprovalsResponse) ProtoMessage() {}
+
+func (x *ListApprovalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListApprovalsResponse.ProtoReflect.Descriptor instead.
+func (*ListApprovalsResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListApprovalsResponse) GetApprovals() []*Approval {
+	if x != nil {
+		return x.Approvals
+	}
+	return nil
+}
+
+type DecideApprovalRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Identity      string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	Approve       bool                   `protobuf:"varint,3,opt,name=approve,proto3" json:"approve,omitempty"`
+	Comment       string                 `protobuf:"bytes,4,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecideApprovalRequest) Reset() {
+	*x = DecideApprovalRequest{}
+	mi := &file_sevalet_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecideApprovalRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecideApprovalRequest) ProtoMessage() {}
+
+func (x *DecideApprovalRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecideApprovalRequest.ProtoReflect.Descriptor instead.
+func (*DecideApprovalRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DecideApprovalRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DecideApprovalRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *DecideApprovalRequest) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+func (x *DecideApprovalRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type ApprovalResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Approval      *Approval              `protobuf:"bytes,3,opt,name=approval,proto3" json:"approval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalResponse) Reset() {
+	*x = ApprovalResponse{}
+	mi := &file_sevalet_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalResponse) ProtoMessage() {}
+
+func (x *ApprovalResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalResponse.ProtoReflect.Descriptor instead.
+func (*ApprovalResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ApprovalResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ApprovalResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *ApprovalResponse) GetApproval() *Approval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+type PlanResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	Plan          []byte                 `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	NextAllowed   string                 `protobuf:"bytes,6,opt,name=next_allowed,json=nextAllowed,proto3" json:"next_allowed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanResponse) Reset() {
+	*x = PlanResponse{}
+	mi := &file_sevalet_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanResponse) ProtoMessage() {}
+
+func (x *PlanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanResponse.ProtoReflect.Descriptor instead.
+func (*PlanResponse) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PlanResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PlanResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *PlanResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PlanResponse) GetPlan() []byte {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *PlanResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *PlanResponse) GetNextAllowed() string {
+	if x != nil {
+		return x.NextAllowed
+	}
+	return ""
+}
+
+type ApplyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Identity      string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyRequest) Reset() {
+	*x = ApplyRequest{}
+	mi := &file_sevalet_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRequest) ProtoMessage() {}
+
+func (x *ApplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sevalet_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return file_sevalet_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ApplyRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ApplyRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+var File_sevalet_proto protoreflect.FileDescriptor
+
+const file_sevalet_proto_rawDesc = "" +
+	"\n" +
+	"\rsevalet.proto\x12\asevalet\"\xc4\x01\n" +
+	"\x0eExecuteRequest\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12\x18\n" +
+	"\atimeout\x18\x03 \x01(\x05R\atimeout\x12\x1f\n" +
+	"\voutput_mode\x18\x04 \x01(\tR\n" +
+	"outputMode\x12-\n" +
+	"\x06filter\x18\x05 \x01(\v2\x15.sevalet.OutputFilterR\x06filter\x12\x1a\n" +
+	"\bidentity\x18\x06 \x01(\tR\bidentity\"\x86\x01\n" +
+	"\fOutputFilter\x12\x12\n" +
+	"\x04head\x18\x01 \x01(\x05R\x04head\x12\x12\n" +
+	"\x04tail\x18\x02 \x01(\x05R\x04tail\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x05R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x05R\x02to\x12\x14\n" +
+	"\x05match\x18\x05 \x01(\tR\x05match\x12\x14\n" +
+	"\x05regex\x18\x06 \x01(\tR\x05regex\"\x85\x05\n" +
+	"\x0fExecuteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\x03 \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x04 \x01(\fR\x06stderr\x12%\n" +
+	"\x0eexecution_time\x18\x05 \x01(\tR\rexecutionTime\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0elimit_exceeded\x18\a \x01(\tR\rlimitExceeded\x12*\n" +
+	"\x11peak_memory_bytes\x18\b \x01(\x04R\x0fpeakMemoryBytes\x12\x19\n" +
+	"\bcpu_time\x18\t \x01(\tR\acpuTime\x12+\n" +
+	"\x06output\x18\n" +
+	" \x03(\v2\x13.sevalet.OutputLineR\x06output\x12\x16\n" +
+	"\x06parsed\x18\v \x01(\fR\x06parsed\x12\x1f\n" +
+	"\vparse_error\x18\f \x01(\tR\n" +
+	"parseError\x12\x1c\n" +
+	"\tsucceeded\x18\r \x01(\bR\tsucceeded\x12\x16\n" +
+	"\x06cached\x18\x0e \x01(\bR\x06cached\x12\x1b\n" +
+	"\tcache_age\x18\x0f \x01(\tR\bcacheAge\x12-\n" +
+	"\bapproval\x18\x10 \x01(\v2\x11.sevalet.ApprovalR\bapproval\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x11 \x01(\tR\terrorCode\x12!\n" +
+	"\fnext_allowed\x18\x12 \x01(\tR\vnextAllowed\x12'\n" +
+	"\x0ftruncated_lines\x18\x13 \x01(\x05R\x0etruncatedLines\"U\n" +
+	"\n" +
+	"OutputLine\x12\x16\n" +
+	"\x06stream\x18\x01 \x01(\tR\x06stream\x12\x1b\n" +
+	"\toffset_ns\x18\x02 \x01(\x03R\boffsetNs\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"w\n" +
+	"\x0fPipelineRequest\x12.\n" +
+	"\x06stages\x18\x01 \x03(\v2\x16.sevalet.PipelineStageR\x06stages\x12\x18\n" +
+	"\atimeout\x18\x02 \x01(\x05R\atimeout\x12\x1a\n" +
+	"\bidentity\x18\x03 \x01(\tR\bidentity\"=\n" +
+	"\rPipelineStage\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\"\x9e\x02\n" +
+	"\x10PipelineResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1c\n" +
+	"\tsucceeded\x18\x02 \x01(\bR\tsucceeded\x12\x16\n" +
+	"\x06stdout\x18\x03 \x01(\fR\x06stdout\x12%\n" +
+	"\x0eexecution_time\x18\x04 \x01(\tR\rexecutionTime\x12#\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\x12,\n" +
+	"\x06stages\x18\x06 \x03(\v2\x14.sevalet.StageResultR\x06stages\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\a \x01(\tR\terrorCode\x12!\n" +
+	"\fnext_allowed\x18\b \x01(\tR\vnextAllowed\"\xa5\x02\n" +
+	"\vStageResult\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x1b\n" +
+	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stderr\x18\x03 \x01(\fR\x06stderr\x12\x16\n" +
+	"\x06signal\x18\x04 \x01(\tR\x06signal\x12\x1c\n" +
+	"\tsucceeded\x18\x05 \x01(\bR\tsucceeded\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\x12%\n" +
+	"\x0elimit_exceeded\x18\a \x01(\tR\rlimitExceeded\x12*\n" +
+	"\x11peak_memory_bytes\x18\b \x01(\x04R\x0fpeakMemoryBytes\x12\x19\n" +
+	"\bcpu_time\x18\t \x01(\tR\acpuTime\"@\n" +
+	"\x0eRunbookRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\"\x82\x02\n" +
+	"\x0fRunbookResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1c\n" +
+	"\tsucceeded\x18\x02 \x01(\bR\tsucceeded\x12%\n" +
+	"\x0eexecution_time\x18\x03 \x01(\tR\rexecutionTime\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\x12)\n" +
+	"\x05steps\x18\x05 \x03(\v2\x13.sevalet.StepResultR\x05steps\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x06 \x01(\tR\terrorCode\x12!\n" +
+	"\fnext_allowed\x18\a \x01(\tR\vnextAllowed\"\xa1\x02\n" +
+	"\n" +
+	"StepResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x03 \x03(\tR\x04args\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12 \n" +
+	"\vcompensates\x18\x05 \x01(\tR\vcompensates\x12\x1b\n" +
+	"\texit_code\x18\x06 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\a \x01(\fR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\b \x01(\fR\x06stderr\x12%\n" +
+	"\x0eexecution_time\x18\t \x01(\tR\rexecutionTime\x12#\n" +
+	"\rerror_message\x18\n" +
+	" \x01(\tR\ferrorMessage\"\x16\n" +
+	"\x14ListSchedulesRequest\"N\n" +
+	"\x15ListSchedulesResponse\x125\n" +
+	"\tschedules\x18\x01 \x03(\v2\x17.sevalet.ScheduleStatusR\tschedules\" \n" +
+	"\x1eWaitScheduleCompletionsRequest\"`\n" +
+	"\x1fWaitScheduleCompletionsResponse\x12=\n" +
+	"\vcompletions\x18\x01 \x03(\v2\x1b.sevalet.ScheduleCompletionR\vcompletions\"\xb7\x01\n" +
+	"\x12ScheduleCompletion\x12\x1a\n" +
+	"\bschedule\x18\x01 \x01(\tR\bschedule\x12!\n" +
+	"\fcallback_url\x18\x02 \x01(\tR\vcallbackUrl\x12\x18\n" +
+	"\astarted\x18\x03 \x01(\tR\astarted\x12\x1a\n" +
+	"\bduration\x18\x04 \x01(\tR\bduration\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xea\x03\n" +
+	"\x0eScheduleStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x02 \x01(\tR\x04cron\x12\x18\n" +
+	"\acommand\x18\x03 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x04 \x03(\tR\x04args\x12\x18\n" +
+	"\arunbook\x18\x05 \x01(\tR\arunbook\x12\x18\n" +
+	"\atimeout\x18\x06 \x01(\x05R\atimeout\x12\x16\n" +
+	"\x06jitter\x18\a \x01(\x05R\x06jitter\x12\x18\n" +
+	"\aoverlap\x18\b \x01(\tR\aoverlap\x12\x18\n" +
+	"\arunning\x18\t \x01(\bR\arunning\x12\x16\n" +
+	"\x06queued\x18\n" +
+	" \x01(\bR\x06queued\x12\x19\n" +
+	"\blast_run\x18\v \x01(\tR\alastRun\x12\x1f\n" +
+	"\vlast_status\x18\f \x01(\tR\n" +
+	"lastStatus\x12#\n" +
+	"\rlast_duration\x18\r \x01(\tR\flastDuration\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x0e \x01(\tR\tlastError\x12\x19\n" +
+	"\bnext_run\x18\x0f \x01(\tR\anextRun\x12\x12\n" +
+	"\x04runs\x18\x10 \x01(\x03R\x04runs\x12\x18\n" +
+	"\askipped\x18\x11 \x01(\x03R\askipped\x12!\n" +
+	"\fcallback_url\x18\x12 \x01(\tR\vcallbackUrl\"\xad\x02\n" +
+	"\bApproval\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12\x12\n" +
+	"\x04args\x18\x03 \x03(\tR\x04args\x12\x1c\n" +
+	"\trequester\x18\x04 \x01(\tR\trequester\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\brequired\x18\x06 \x01(\x05R\brequired\x12+\n" +
+	"\x05votes\x18\a \x03(\v2\x15.sevalet.ApprovalVoteR\x05votes\x12\x18\n" +
+	"\acreated\x18\b \x01(\tR\acreated\x12\x18\n" +
+	"\aexpires\x18\t \x01(\tR\aexpires\x120\n" +
+	"\x06result\x18\n" +
+	" \x01(\v2\x18.sevalet.ExecuteResponseR\x06result\"r\n" +
+	"\fApprovalVote\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x12\x18\n" +
+	"\acomment\x18\x03 \x01(\tR\acomment\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\tR\x04time\"$\n" +
+	"\x12GetApprovalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x16\n" +
+	"\x14ListApprovalsRequest\"H\n" +
+	"\x15ListApprovalsResponse\x12/\n" +
+	"\tapprovals\x18\x01 \x03(\v2\x11.sevalet.ApprovalR\tapprovals\"w\n" +
+	"\x15DecideApprovalRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x18\n" +
+	"\aapprove\x18\x03 \x01(\bR\aapprove\x12\x18\n" +
+	"\acomment\x18\x04 \x01(\tR\acomment\"\x80\x01\n" +
+	"\x10ApprovalResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\x12-\n" +
+	"\bapproval\x18\x03 \x01(\v2\x11.sevalet.ApprovalR\bapproval\"\xb9\x01\n" +
+	"\fPlanResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12\x12\n" +
+	"\x04plan\x18\x04 \x01(\fR\x04plan\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\x12!\n" +
+	"\fnext_allowed\x18\x06 \x01(\tR\vnextAllowed\"@\n" +
+	"\fApplyRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity2\xf0\x05\n" +
 	"\x0fCommandExecutor\x12<\n" +
-	"\aExecute\x12\x17.sevalet.ExecuteRequest\x1a\x18.sevalet.ExecuteResponseB\x06Z\x04./pbb\x06proto3"
+	"\aExecute\x12\x17.sevalet.ExecuteRequest\x1a\x18.sevalet.ExecuteResponse\x12F\n" +
+	"\x0fExecutePipeline\x12\x18.sevalet.PipelineRequest\x1a\x19.sevalet.PipelineResponse\x12C\n" +
+	"\x0eExecuteRunbook\x12\x17.sevalet.RunbookRequest\x1a\x18.sevalet.RunbookResponse\x12N\n" +
+	"\rListSchedules\x12\x1d.sevalet.ListSchedulesRequest\x1a\x1e.sevalet.ListSchedulesResponse\x12E\n" +
+	"\vGetApproval\x12\x1b.sevalet.GetApprovalRequest\x1a\x19.sevalet.ApprovalResponse\x12N\n" +
+	"\rListApprovals\x12\x1d.sevalet.ListApprovalsRequest\x1a\x1e.sevalet.ListApprovalsResponse\x12K\n" +
+	"\x0eDecideApproval\x12\x1e.sevalet.DecideApprovalRequest\x1a\x19.sevalet.ApprovalResponse\x126\n" +
+	"\x04Plan\x12\x17.sevalet.ExecuteRequest\x1a\x15.sevalet.PlanResponse\x128\n" +
+	"\x05Apply\x12\x15.sevalet.ApplyRequest\x1a\x18.sevalet.ExecuteResponse\x12l\n" +
+	"\x17WaitScheduleCompletions\x12'.sevalet.WaitScheduleCompletionsRequest\x1a(.sevalet.WaitScheduleCompletionsResponseB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_sevalet_proto_rawDescOnce sync.Once
@@ -196,19 +2214,73 @@ func file_sevalet_proto_rawDescGZIP() []byte {
 	return file_sevalet_proto_rawDescData
 }
 
-var file_sevalet_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_sevalet_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_sevalet_proto_goTypes = []any{
-	(*ExecuteRequest)(nil),  // 0: sevalet.ExecuteRequest
-	(*ExecuteResponse)(nil), // 1: sevalet.ExecuteResponse
+	(*ExecuteRequest)(nil),                  // 0: sevalet.ExecuteRequest
+	(*OutputFilter)(nil),                    // 1: sevalet.OutputFilter
+	(*ExecuteResponse)(nil),                 // 2: sevalet.ExecuteResponse
+	(*OutputLine)(nil),                      // 3: sevalet.OutputLine
+	(*PipelineRequest)(nil),                 // 4: sevalet.PipelineRequest
+	(*PipelineStage)(nil),                   // 5: sevalet.PipelineStage
+	(*PipelineResponse)(nil),                // 6: sevalet.PipelineResponse
+	(*StageResult)(nil),                     // 7: sevalet.StageResult
+	(*RunbookRequest)(nil),                  // 8: sevalet.RunbookRequest
+	(*RunbookResponse)(nil),                 // 9: sevalet.RunbookResponse
+	(*StepResult)(nil),                      // 10: sevalet.StepResult
+	(*ListSchedulesRequest)(nil),            // 11: sevalet.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),           // 12: sevalet.ListSchedulesResponse
+	(*WaitScheduleCompletionsRequest)(nil),  // 13: sevalet.WaitScheduleCompletionsRequest
+	(*WaitScheduleCompletionsResponse)(nil), // 14: sevalet.WaitScheduleCompletionsResponse
+	(*ScheduleCompletion)(nil),              // 15: sevalet.ScheduleCompletion
+	(*ScheduleStatus)(nil),                  // 16: sevalet.ScheduleStatus
+	(*Approval)(nil),                        // 17: sevalet.Approval
+	(*ApprovalVote)(nil),                    // 18: sevalet.ApprovalVote
+	(*GetApprovalRequest)(nil),              // 19: sevalet.GetApprovalRequest
+	(*ListApprovalsRequest)(nil),            // 20: sevalet.ListApprovalsRequest
+	(*ListApprovalsResponse)(nil),           // 21: sevalet.ListApprovalsResponse
+	(*DecideApprovalRequest)(nil),           // 22: sevalet.DecideApprovalRequest
+	(*ApprovalResponse)(nil),                // 23: sevalet.ApprovalResponse
+	(*PlanResponse)(nil),                    // 24: sevalet.PlanResponse
+	(*ApplyRequest)(nil),                    // 25: sevalet.ApplyRequest
 }
 var file_sevalet_proto_depIdxs = []int32{
-	0, // 0: sevalet.CommandExecutor.Execute:input_type -> sevalet.ExecuteRequest
-	1, // 1: sevalet.CommandExecutor.Execute:output_type -> sevalet.ExecuteResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: sevalet.ExecuteRequest.filter:type_name -> sevalet.OutputFilter
+	3,  // 1: sevalet.ExecuteResponse.output:type_name -> sevalet.OutputLine
+	17, // 2: sevalet.ExecuteResponse.approval:type_name -> sevalet.Approval
+	5,  // 3: sevalet.PipelineRequest.stages:type_name -> sevalet.PipelineStage
+	7,  // 4: sevalet.PipelineResponse.stages:type_name -> sevalet.StageResult
+	10, // 5: sevalet.RunbookResponse.steps:type_name -> sevalet.StepResult
+	16, // 6: sevalet.ListSchedulesResponse.schedules:type_name -> sevalet.ScheduleStatus
+	15, // 7: sevalet.WaitScheduleCompletionsResponse.completions:type_name -> sevalet.ScheduleCompletion
+	18, // 8: sevalet.Approval.votes:type_name -> sevalet.ApprovalVote
+	2,  // 9: sevalet.Approval.result:type_name -> sevalet.ExecuteResponse
+	17, // 10: sevalet.ListApprovalsResponse.approvals:type_name -> sevalet.Approval
+	17, // 11: sevalet.ApprovalResponse.approval:type_name -> sevalet.Approval
+	0,  // 12: sevalet.CommandExecutor.Execute:input_type -> sevalet.ExecuteRequest
+	4,  // 13: sevalet.CommandExecutor.ExecutePipeline:input_type -> sevalet.PipelineRequest
+	8,  // 14: sevalet.CommandExecutor.ExecuteRunbook:input_type -> sevalet.RunbookRequest
+	11, // 15: sevalet.CommandExecutor.ListSchedules:input_type -> sevalet.ListSchedulesRequest
+	19, // 16: sevalet.CommandExecutor.GetApproval:input_type -> sevalet.GetApprovalRequest
+	20, // 17: sevalet.CommandExecutor.ListApprovals:input_type -> sevalet.ListApprovalsRequest
+	22, // 18: sevalet.CommandExecutor.DecideApproval:input_type -> sevalet.DecideApprovalRequest
+	0,  // 19: sevalet.CommandExecutor.Plan:input_type -> sevalet.ExecuteRequest
+	25, // 20: sevalet.CommandExecutor.Apply:input_type -> sevalet.ApplyRequest
+	13, // 21: sevalet.CommandExecutor.WaitScheduleCompletions:input_type -> sevalet.WaitScheduleCompletionsRequest
+	2,  // 22: sevalet.CommandExecutor.Execute:output_type -> sevalet.ExecuteResponse
+	6,  // 23: sevalet.CommandExecutor.ExecutePipeline:output_type -> sevalet.PipelineResponse
+	9,  // 24: sevalet.CommandExecutor.ExecuteRunbook:output_type -> sevalet.RunbookResponse
+	12, // 25: sevalet.CommandExecutor.ListSchedules:output_type -> sevalet.ListSchedulesResponse
+	23, // 26: sevalet.CommandExecutor.GetApproval:output_type -> sevalet.ApprovalResponse
+	21, // 27: sevalet.CommandExecutor.ListApprovals:output_type -> sevalet.ListApprovalsResponse
+	23, // 28: sevalet.CommandExecutor.DecideApproval:output_type -> sevalet.ApprovalResponse
+	24, // 29: sevalet.CommandExecutor.Plan:output_type -> sevalet.PlanResponse
+	2,  // 30: sevalet.CommandExecutor.Apply:output_type -> sevalet.ExecuteResponse
+	14, // 31: sevalet.CommandExecutor.WaitScheduleCompletions:output_type -> sevalet.WaitScheduleCompletionsResponse
+	22, // [22:32] is the sub-list for method output_type
+	12, // [12:22] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_sevalet_proto_init() }
@@ -222,7 +2294,7 @@ func file_sevalet_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sevalet_proto_rawDesc), len(file_sevalet_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
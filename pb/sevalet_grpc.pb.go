@@ -19,7 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	CommandExecutor_Execute_FullMethodName = "/sevalet.CommandExecutor/Execute"
+	CommandExecutor_Execute_FullMethodName                 = "/sevalet.CommandExecutor/Execute"
+	CommandExecutor_ExecutePipeline_FullMethodName         = "/sevalet.CommandExecutor/ExecutePipeline"
+	CommandExecutor_ExecuteRunbook_FullMethodName          = "/sevalet.CommandExecutor/ExecuteRunbook"
+	CommandExecutor_ListSchedules_FullMethodName           = "/sevalet.CommandExecutor/ListSchedules"
+	CommandExecutor_GetApproval_FullMethodName             = "/sevalet.CommandExecutor/GetApproval"
+	CommandExecutor_ListApprovals_FullMethodName           = "/sevalet.CommandExecutor/ListApprovals"
+	CommandExecutor_DecideApproval_FullMethodName          = "/sevalet.CommandExecutor/DecideApproval"
+	CommandExecutor_Plan_FullMethodName                    = "/sevalet.CommandExecutor/Plan"
+	CommandExecutor_Apply_FullMethodName                   = "/sevalet.CommandExecutor/Apply"
+	CommandExecutor_WaitScheduleCompletions_FullMethodName = "/sevalet.CommandExecutor/WaitScheduleCompletions"
 )
 
 // CommandExecutorClient is the client API for CommandExecutor service.
@@ -27,6 +36,15 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CommandExecutorClient interface {
 	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	ExecutePipeline(ctx context.Context, in *PipelineRequest, opts ...grpc.CallOption) (*PipelineResponse, error)
+	ExecuteRunbook(ctx context.Context, in *RunbookRequest, opts ...grpc.CallOption) (*RunbookResponse, error)
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error)
+	GetApproval(ctx context.Context, in *GetApprovalRequest, opts ...grpc.CallOption) (*ApprovalResponse, error)
+	ListApprovals(ctx context.Context, in *ListApprovalsRequest, opts ...grpc.CallOption) (*ListApprovalsResponse, error)
+	DecideApproval(ctx context.Context, in *DecideApprovalRequest, opts ...grpc.CallOption) (*ApprovalResponse, error)
+	Plan(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*PlanResponse, error)
+	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	WaitScheduleCompletions(ctx context.Context, in *WaitScheduleCompletionsRequest, opts ...grpc.CallOption) (*WaitScheduleCompletionsResponse, error)
 }
 
 type commandExecutorClient struct {
@@ -47,11 +65,110 @@ func (c *commandExecutorClient) Execute(ctx context.Context, in *ExecuteRequest,
 	return out, nil
 }
 
+func (c *commandExecutorClient) ExecutePipeline(ctx context.Context, in *PipelineRequest, opts ...grpc.CallOption) (*PipelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PipelineResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_ExecutePipeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) ExecuteRunbook(ctx context.Context, in *RunbookRequest, opts ...grpc.CallOption) (*RunbookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunbookResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_ExecuteRunbook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSchedulesResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_ListSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) GetApproval(ctx context.Context, in *GetApprovalRequest, opts ...grpc.CallOption) (*ApprovalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApprovalResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_GetApproval_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) ListApprovals(ctx context.Context, in *ListApprovalsRequest, opts ...grpc.CallOption) (*ListApprovalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListApprovalsResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_ListApprovals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) DecideApproval(ctx context.Context, in *DecideApprovalRequest, opts ...grpc.CallOption) (*ApprovalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApprovalResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_DecideApproval_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) Plan(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*PlanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlanResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_Plan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ExecuteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_Apply_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commandExecutorClient) WaitScheduleCompletions(ctx context.Context, in *WaitScheduleCompletionsRequest, opts ...grpc.CallOption) (*WaitScheduleCompletionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitScheduleCompletionsResponse)
+	err := c.cc.Invoke(ctx, CommandExecutor_WaitScheduleCompletions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CommandExecutorServer is the server API for CommandExecutor service.
 // All implementations must embed UnimplementedCommandExecutorServer
 // for forward compatibility
 type CommandExecutorServer interface {
 	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	ExecutePipeline(context.Context, *PipelineRequest) (*PipelineResponse, error)
+	ExecuteRunbook(context.Context, *RunbookRequest) (*RunbookResponse, error)
+	ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	GetApproval(context.Context, *GetApprovalRequest) (*ApprovalResponse, error)
+	ListApprovals(context.Context, *ListApprovalsRequest) (*ListApprovalsResponse, error)
+	DecideApproval(context.Context, *DecideApprovalRequest) (*ApprovalResponse, error)
+	Plan(context.Context, *ExecuteRequest) (*PlanResponse, error)
+	Apply(context.Context, *ApplyRequest) (*ExecuteResponse, error)
+	WaitScheduleCompletions(context.Context, *WaitScheduleCompletionsRequest) (*WaitScheduleCompletionsResponse, error)
 	mustEmbedUnimplementedCommandExecutorServer()
 }
 
@@ -62,6 +179,33 @@ type UnimplementedCommandExecutorServer struct {
 func (UnimplementedCommandExecutorServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
 }
+func (UnimplementedCommandExecutorServer) ExecutePipeline(context.Context, *PipelineRequest) (*PipelineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecutePipeline not implemented")
+}
+func (UnimplementedCommandExecutorServer) ExecuteRunbook(context.Context, *RunbookRequest) (*RunbookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteRunbook not implemented")
+}
+func (UnimplementedCommandExecutorServer) ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedCommandExecutorServer) GetApproval(context.Context, *GetApprovalRequest) (*ApprovalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApproval not implemented")
+}
+func (UnimplementedCommandExecutorServer) ListApprovals(context.Context, *ListApprovalsRequest) (*ListApprovalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListApprovals not implemented")
+}
+func (UnimplementedCommandExecutorServer) DecideApproval(context.Context, *DecideApprovalRequest) (*ApprovalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecideApproval not implemented")
+}
+func (UnimplementedCommandExecutorServer) Plan(context.Context, *ExecuteRequest) (*PlanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Plan not implemented")
+}
+func (UnimplementedCommandExecutorServer) Apply(context.Context, *ApplyRequest) (*ExecuteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Apply not implemented")
+}
+func (UnimplementedCommandExecutorServer) WaitScheduleCompletions(context.Context, *WaitScheduleCompletionsRequest) (*WaitScheduleCompletionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaitScheduleCompletions not implemented")
+}
 func (UnimplementedCommandExecutorServer) mustEmbedUnimplementedCommandExecutorServer() {}
 
 // UnsafeCommandExecutorServer may be embedded to opt out of forward compatibility for this service.
@@ -93,6 +237,168 @@ func _CommandExecutor_Execute_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CommandExecutor_ExecutePipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PipelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).ExecutePipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_ExecutePipeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).ExecutePipeline(ctx, req.(*PipelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_ExecuteRunbook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunbookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).ExecuteRunbook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_ExecuteRunbook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).ExecuteRunbook(ctx, req.(*RunbookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_ListSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_GetApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).GetApproval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_GetApproval_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).GetApproval(ctx, req.(*GetApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_ListApprovals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApprovalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).ListApprovals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_ListApprovals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).ListApprovals(ctx, req.(*ListApprovalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_DecideApproval_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecideApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).DecideApproval(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_DecideApproval_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).DecideApproval(ctx, req.(*DecideApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_Plan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).Plan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_Plan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).Plan(ctx, req.(*ExecuteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_Apply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).Apply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_Apply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).Apply(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CommandExecutor_WaitScheduleCompletions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitScheduleCompletionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommandExecutorServer).WaitScheduleCompletions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CommandExecutor_WaitScheduleCompletions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommandExecutorServer).WaitScheduleCompletions(ctx, req.(*WaitScheduleCompletionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CommandExecutor_ServiceDesc is the grpc.ServiceDesc for CommandExecutor service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -104,6 +410,42 @@ var CommandExecutor_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Execute",
 			Handler:    _CommandExecutor_Execute_Handler,
 		},
+		{
+			MethodName: "ExecutePipeline",
+			Handler:    _CommandExecutor_ExecutePipeline_Handler,
+		},
+		{
+			MethodName: "ExecuteRunbook",
+			Handler:    _CommandExecutor_ExecuteRunbook_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _CommandExecutor_ListSchedules_Handler,
+		},
+		{
+			MethodName: "GetApproval",
+			Handler:    _CommandExecutor_GetApproval_Handler,
+		},
+		{
+			MethodName: "ListApprovals",
+			Handler:    _CommandExecutor_ListApprovals_Handler,
+		},
+		{
+			MethodName: "DecideApproval",
+			Handler:    _CommandExecutor_DecideApproval_Handler,
+		},
+		{
+			MethodName: "Plan",
+			Handler:    _CommandExecutor_Plan_Handler,
+		},
+		{
+			MethodName: "Apply",
+			Handler:    _CommandExecutor_Apply_Handler,
+		},
+		{
+			MethodName: "WaitScheduleCompletions",
+			Handler:    _CommandExecutor_WaitScheduleCompletions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "sevalet.proto",